@@ -0,0 +1,57 @@
+// Command blobsweeper cancels resumable upload sessions (see
+// internal/blobupload) that expired without being finished, freeing any
+// chunks they wrote to storage that nothing else retained. It runs once and
+// exits; operators wire it onto a scheduler (cron, a Kubernetes CronJob)
+// rather than having it loop in-process.
+package main
+
+import (
+	"context"
+	"log"
+
+	"github.com/Parallels/pd-ai-agents-registry/internal/blobupload"
+	"github.com/Parallels/pd-ai-agents-registry/internal/config"
+	"github.com/Parallels/pd-ai-agents-registry/internal/db/mongodb"
+	"github.com/Parallels/pd-ai-agents-registry/internal/logger"
+	"github.com/Parallels/pd-ai-agents-registry/internal/worker"
+)
+
+func main() {
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	logger := logger.NewLogger(cfg.AppEnv)
+	defer func() {
+		if err := logger.Sync(); err != nil {
+			log.Printf("Failed to sync logger: %v", err)
+		}
+	}()
+
+	ctx := context.Background()
+
+	mongoClient, err := mongodb.NewClient(ctx, cfg.MongoDB)
+	if err != nil {
+		logger.Fatal("Failed to connect to MongoDB", err)
+	}
+	defer func() {
+		if err := mongoClient.Disconnect(ctx); err != nil {
+			log.Printf("Failed to disconnect from MongoDB: %v", err)
+		}
+	}()
+
+	tasks := worker.NewClient(cfg.Redis)
+	defer func() {
+		if err := tasks.Close(); err != nil {
+			log.Printf("Failed to close task client: %v", err)
+		}
+	}()
+
+	swept, err := blobupload.Sweep(ctx, mongoClient, tasks, logger)
+	if err != nil {
+		logger.Fatal("Sweep run failed", err)
+	}
+
+	logger.Info("Sweep run finished", "sessions_swept", swept)
+}
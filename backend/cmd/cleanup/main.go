@@ -0,0 +1,70 @@
+// Command cleanup runs the retention worker that prunes old package
+// versions (see internal/cleanup). It runs once and exits; operators wire it
+// onto a scheduler (cron, a Kubernetes CronJob) at the cadence set by
+// cleanup.interval_hour rather than having it loop in-process.
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+
+	"github.com/Parallels/pd-ai-agents-registry/internal/cleanup"
+	"github.com/Parallels/pd-ai-agents-registry/internal/config"
+	"github.com/Parallels/pd-ai-agents-registry/internal/db/mongodb"
+	"github.com/Parallels/pd-ai-agents-registry/internal/logger"
+	"github.com/Parallels/pd-ai-agents-registry/internal/worker"
+)
+
+func main() {
+	dryRun := flag.Bool("dry-run", false, "log what would be pruned without deleting anything")
+	force := flag.Bool("force", false, "run even when cleanup.enabled is false in config")
+	flag.Parse()
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	if !cfg.Cleanup.Enabled && !*dryRun && !*force {
+		log.Fatal("cleanup.enabled is false; pass -dry-run to preview or -force to run anyway")
+	}
+
+	logger := logger.NewLogger(cfg.AppEnv)
+	defer func() {
+		if err := logger.Sync(); err != nil {
+			log.Printf("Failed to sync logger: %v", err)
+		}
+	}()
+
+	ctx := context.Background()
+
+	mongoClient, err := mongodb.NewClient(ctx, cfg.MongoDB)
+	if err != nil {
+		logger.Fatal("Failed to connect to MongoDB", err)
+	}
+	defer func() {
+		if err := mongoClient.Disconnect(ctx); err != nil {
+			log.Printf("Failed to disconnect from MongoDB: %v", err)
+		}
+	}()
+
+	var tasks *worker.Client
+	if !*dryRun {
+		tasks = worker.NewClient(cfg.Redis)
+		defer func() {
+			if err := tasks.Close(); err != nil {
+				log.Printf("Failed to close task client: %v", err)
+			}
+		}()
+	}
+
+	runner := cleanup.NewCleanup(mongoClient, tasks, logger, cfg.Cleanup)
+
+	summary, err := runner.Run(ctx, *dryRun)
+	if err != nil {
+		logger.Fatal("Cleanup run failed", err)
+	}
+
+	logger.Info("Cleanup run finished", "dry_run", *dryRun, "deleted", summary.Deleted, "kept", summary.Kept, "skipped", summary.Skipped)
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"log"
+
+	"github.com/Parallels/pd-ai-agents-registry/internal/config"
+	"github.com/Parallels/pd-ai-agents-registry/internal/db/mongodb"
+	"github.com/Parallels/pd-ai-agents-registry/internal/logger"
+	"github.com/Parallels/pd-ai-agents-registry/internal/signing"
+	"github.com/Parallels/pd-ai-agents-registry/internal/storage"
+	"github.com/Parallels/pd-ai-agents-registry/internal/worker"
+	"github.com/hibiken/asynq"
+)
+
+func main() {
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	logger := logger.NewLogger(cfg.AppEnv)
+	defer func() {
+		if err := logger.Sync(); err != nil {
+			log.Printf("Failed to sync logger: %v", err)
+		}
+	}()
+
+	mongoClient, err := mongodb.NewClient(context.Background(), cfg.MongoDB)
+	if err != nil {
+		logger.Fatal("Failed to connect to MongoDB", err)
+	}
+	defer func() {
+		if err := mongoClient.Disconnect(context.Background()); err != nil {
+			log.Printf("Failed to disconnect from MongoDB: %v", err)
+		}
+	}()
+
+	backend, err := storage.New(cfg.Storage)
+	if err != nil {
+		logger.Fatal("Failed to initialize storage backend", err)
+	}
+
+	verifier, err := signing.NewVerifier(cfg.Signing.TrustedKeys)
+	if err != nil {
+		logger.Fatal("Failed to load trusted signing keys", err)
+	}
+
+	processor := worker.NewProcessor(mongoClient, backend, logger, verifier)
+	mux := asynq.NewServeMux()
+	processor.RegisterHandlers(mux)
+
+	srv := asynq.NewServer(
+		asynq.RedisClientOpt{
+			Addr:     cfg.Redis.Addr,
+			Password: cfg.Redis.Password,
+			DB:       cfg.Redis.DB,
+		},
+		asynq.Config{Concurrency: 10},
+	)
+
+	logger.Info("Starting package-processing worker")
+	if err := srv.Run(mux); err != nil {
+		logger.Fatal("Worker server stopped", err)
+	}
+}
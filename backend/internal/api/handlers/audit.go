@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/Parallels/pd-ai-agents-registry/internal/db/mongodb"
+	"github.com/Parallels/pd-ai-agents-registry/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// AuditLogResponse is the paginated response for GET /audit-log.
+type AuditLogResponse struct {
+	Items   []models.AuditEntry `json:"items"`
+	Total   int64               `json:"total"`
+	Page    int                 `json:"page"`
+	PerPage int                 `json:"per_page"`
+}
+
+// @Summary Query the audit log
+// @Description Read back the append-only audit trail of package/version mutations (admin only)
+// @Tags admin
+// @Produce json
+// @Param actor query string false "Filter by the actor that made the change"
+// @Param action query string false "Filter by action, e.g. yank_version"
+// @Param target query string false "Filter by target, e.g. a packageID@version"
+// @Param page query int false "Page number, 1-indexed"
+// @Param per_page query int false "Results per page"
+// @Security BearerAuth
+// @Success 200 {object} AuditLogResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /audit-log [get]
+func (h *Handler) QueryAuditLog(c *gin.Context) {
+	filter := mongodb.AuditQueryFilter{
+		Actor:  c.Query("actor"),
+		Action: c.Query("action"),
+		Target: c.Query("target"),
+	}
+	filter.Page = 1
+	if raw := c.Query("page"); raw != "" {
+		if v, err := strconv.Atoi(raw); err == nil && v > 0 {
+			filter.Page = v
+		}
+	}
+	filter.PerPage = 20
+	if raw := c.Query("per_page"); raw != "" {
+		if v, err := strconv.Atoi(raw); err == nil && v > 0 {
+			filter.PerPage = v
+		}
+	}
+
+	entries, total, err := h.db.QueryAudit(c.Request.Context(), filter)
+	if err != nil {
+		h.logger.Error("Failed to query audit log", "error", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to retrieve audit log"})
+		return
+	}
+
+	c.JSON(http.StatusOK, AuditLogResponse{
+		Items:   entries,
+		Total:   total,
+		Page:    filter.Page,
+		PerPage: filter.PerPage,
+	})
+}
@@ -4,10 +4,10 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/Parallels/pd-ai-agents-registry/internal/auth"
 	"github.com/Parallels/pd-ai-agents-registry/internal/models"
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt/v5"
-	"golang.org/x/crypto/bcrypt"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type LoginRequest struct {
@@ -21,12 +21,21 @@ type RegisterRequest struct {
 	Email    string `json:"email" binding:"required,email"`
 }
 
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
 type AuthResponse struct {
-	Token string `json:"token"`
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
 }
 
 // @Summary User login
-// @Description Authenticate user and return JWT token
+// @Description Authenticate user and return an access/refresh token pair
 // @Tags auth
 // @Accept json
 // @Produce json
@@ -42,7 +51,6 @@ func (h *Handler) Login(c *gin.Context) {
 		return
 	}
 
-	// Get user from database
 	user, err := h.db.GetUserByUsername(c.Request.Context(), req.Username)
 	if err != nil {
 		h.logger.Error("Failed to get user", "error", err)
@@ -55,26 +63,29 @@ func (h *Handler) Login(c *gin.Context) {
 		return
 	}
 
-	// Check password
-	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)); err != nil {
+	ok, err := auth.VerifyPassword(user.Password, req.Password)
+	if err != nil {
+		h.logger.Error("Failed to verify password", "error", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Internal server error"})
+		return
+	}
+	if !ok {
 		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "Invalid credentials"})
 		return
 	}
 
-	// Generate JWT token
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"sub": user.ID.Hex(),
-		"exp": time.Now().Add(time.Duration(h.cfg.JWT.ExpiryHour) * time.Hour).Unix(),
-	})
-
-	tokenString, err := token.SignedString([]byte(h.cfg.JWT.Secret))
-	if err != nil {
-		h.logger.Error("Failed to generate token", "error", err)
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to generate token"})
-		return
+	// Transparently upgrade legacy bcrypt hashes to argon2id now that we
+	// know the plaintext password. Best-effort: a failure here shouldn't
+	// block the login that triggered it.
+	if user.PasswordAlgorithm != auth.AlgorithmArgon2id {
+		if upgraded, err := auth.HashPassword(req.Password); err != nil {
+			h.logger.Error("Failed to upgrade password hash", "error", err)
+		} else if err := h.db.UpdateUserPassword(c.Request.Context(), user.ID, upgraded, auth.AlgorithmArgon2id); err != nil {
+			h.logger.Error("Failed to persist upgraded password hash", "error", err)
+		}
 	}
 
-	c.JSON(http.StatusOK, AuthResponse{Token: tokenString})
+	h.issueTokenPair(c, user)
 }
 
 // @Summary User registration
@@ -94,7 +105,6 @@ func (h *Handler) Register(c *gin.Context) {
 		return
 	}
 
-	// Check if username exists
 	existingUser, err := h.db.GetUserByUsername(c.Request.Context(), req.Username)
 	if err != nil {
 		h.logger.Error("Failed to check username", "error", err)
@@ -107,19 +117,18 @@ func (h *Handler) Register(c *gin.Context) {
 		return
 	}
 
-	// Hash password
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	hashedPassword, err := auth.HashPassword(req.Password)
 	if err != nil {
 		h.logger.Error("Failed to hash password", "error", err)
 		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Internal server error"})
 		return
 	}
 
-	// Create user with correct model
 	user := &models.User{
-		Username: req.Username,
-		Password: string(hashedPassword),
-		Email:    req.Email,
+		Username:          req.Username,
+		Password:          hashedPassword,
+		PasswordAlgorithm: auth.AlgorithmArgon2id,
+		Email:             req.Email,
 	}
 
 	if err := h.db.CreateUser(c.Request.Context(), user); err != nil {
@@ -128,18 +137,162 @@ func (h *Handler) Register(c *gin.Context) {
 		return
 	}
 
-	// Generate JWT token with correct ID access
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"sub": user.ID.Hex(),
-		"exp": time.Now().Add(time.Duration(h.cfg.JWT.ExpiryHour) * time.Hour).Unix(),
-	})
+	h.issueTokenPair(c, user)
+}
+
+// @Summary Refresh access token
+// @Description Exchange a refresh token for a new access/refresh token pair
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param body body RefreshRequest true "Refresh token"
+// @Success 200 {object} AuthResponse
+// @Failure 401 {object} ErrorResponse
+// @Router /auth/refresh [post]
+func (h *Handler) Refresh(c *gin.Context) {
+	var req RefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid request"})
+		return
+	}
+
+	hash := auth.HashRefreshToken(req.RefreshToken)
+	stored, err := h.db.GetRefreshToken(c.Request.Context(), hash)
+	if err != nil {
+		h.logger.Error("Failed to get refresh token", "error", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Internal server error"})
+		return
+	}
+	if stored == nil || stored.RevokedAt != nil || stored.ExpiresAt.Before(time.Now()) {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "Invalid refresh token"})
+		return
+	}
+
+	user, err := h.db.GetUserByID(c.Request.Context(), stored.UserID)
+	if err != nil {
+		h.logger.Error("Failed to get user", "error", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Internal server error"})
+		return
+	}
+	if user == nil {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "Invalid refresh token"})
+		return
+	}
+
+	// Rotate: the presented refresh token is single-use.
+	if err := h.db.RevokeRefreshToken(c.Request.Context(), hash); err != nil {
+		h.logger.Error("Failed to revoke refresh token", "error", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Internal server error"})
+		return
+	}
+
+	h.issueTokenPair(c, user)
+}
+
+// @Summary Logout
+// @Description Revoke the current access token and the refresh token used to obtain it
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param body body LogoutRequest true "Refresh token"
+// @Success 204
+// @Router /auth/logout [post]
+func (h *Handler) Logout(c *gin.Context) {
+	var req LogoutRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid request"})
+		return
+	}
+
+	if err := h.db.RevokeRefreshToken(c.Request.Context(), auth.HashRefreshToken(req.RefreshToken)); err != nil {
+		h.logger.Error("Failed to revoke refresh token", "error", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Internal server error"})
+		return
+	}
+
+	if jti, ok := c.Get("jti"); ok {
+		if expiresAt, ok := c.Get("jti_expires_at"); ok {
+			if err := h.db.DenylistJTI(c.Request.Context(), jti.(string), expiresAt.(time.Time)); err != nil {
+				h.logger.Error("Failed to denylist access token", "error", err)
+			}
+		}
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// @Summary Logout everywhere
+// @Description Revoke every refresh token belonging to the authenticated user
+// @Tags auth
+// @Produce json
+// @Success 204
+// @Router /auth/logout-all [post]
+func (h *Handler) LogoutAll(c *gin.Context) {
+	userID, ok := c.Get("user_id")
+	if !ok {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "Unauthorized"})
+		return
+	}
+
+	objectID, err := primitive.ObjectIDFromHex(userID.(string))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "Unauthorized"})
+		return
+	}
+
+	if err := h.db.RevokeAllRefreshTokens(c.Request.Context(), objectID); err != nil {
+		h.logger.Error("Failed to revoke refresh tokens", "error", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Internal server error"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
 
-	tokenString, err := token.SignedString([]byte(h.cfg.JWT.Secret))
+// @Summary JSON Web Key Set
+// @Description Public keys for verifying access tokens, when jwt.algorithm is RS256 or ES256
+// @Tags auth
+// @Produce json
+// @Success 200 {object} auth.JWKS
+// @Failure 404 {object} ErrorResponse
+// @Router /.well-known/jwks.json [get]
+func (h *Handler) JWKS(c *gin.Context) {
+	jwks, ok := h.tokens.JWKS()
+	if !ok {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "This server signs tokens symmetrically and has no public key to publish"})
+		return
+	}
+	c.JSON(http.StatusOK, jwks)
+}
+
+// issueTokenPair signs a fresh access token and mints a fresh refresh token
+// for user, persists the refresh token, and writes the pair to the
+// response. It's shared by Login, Register, and Refresh.
+func (h *Handler) issueTokenPair(c *gin.Context, user *models.User) {
+	accessToken, _, _, err := h.tokens.IssueAccessToken(user.ID.Hex(), user.Roles)
+	if err != nil {
+		h.logger.Error("Failed to issue access token", "error", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to generate token"})
+		return
+	}
+
+	refreshToken, refreshHash, err := auth.NewRefreshToken()
 	if err != nil {
-		h.logger.Error("Failed to generate token", "error", err)
+		h.logger.Error("Failed to issue refresh token", "error", err)
 		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to generate token"})
 		return
 	}
 
-	c.JSON(http.StatusOK, AuthResponse{Token: tokenString})
+	record := &models.RefreshToken{
+		UserID:    user.ID,
+		TokenHash: refreshHash,
+		ExpiresAt: time.Now().Add(h.tokens.RefreshTTL()),
+	}
+	if err := h.db.CreateRefreshToken(c.Request.Context(), record); err != nil {
+		h.logger.Error("Failed to store refresh token", "error", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Internal server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, AuthResponse{Token: accessToken, RefreshToken: refreshToken})
 }
@@ -0,0 +1,362 @@
+package handlers
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/Parallels/pd-ai-agents-registry/internal/blobupload"
+	"github.com/Parallels/pd-ai-agents-registry/internal/chunkstore"
+	"github.com/Parallels/pd-ai-agents-registry/internal/models"
+	"github.com/Parallels/pd-ai-agents-registry/internal/worker"
+	"github.com/gin-gonic/gin"
+)
+
+// StartBlobUploadRequest optionally tells the backend the file's content
+// type up front, same as InitiateUploadRequest for the presigned multipart
+// path.
+type StartBlobUploadRequest struct {
+	ContentType string `json:"content_type"`
+}
+
+// StartBlobUploadResponse identifies a new resumable upload session the
+// client now PATCHes bytes against.
+type StartBlobUploadResponse struct {
+	UploadID string `json:"upload_id"`
+}
+
+// BlobUploadStatusResponse reports how many bytes a session has received so
+// far, so an interrupted CLI upload knows where to resume from.
+type BlobUploadStatusResponse struct {
+	UploadID      string    `json:"upload_id"`
+	ReceivedBytes int64     `json:"received_bytes"`
+	ExpiresAt     time.Time `json:"expires_at"`
+}
+
+// FinishBlobUploadRequest supplies the digest the client expects the
+// reassembled upload to hash to; Finish refuses to record the file if it
+// doesn't match.
+type FinishBlobUploadRequest struct {
+	Sha256 string `json:"sha256" binding:"required"`
+}
+
+// @Summary Start a resumable upload
+// @Description Start a chunked, resumable upload session for a package file
+// @Tags packages
+// @Accept json
+// @Produce json
+// @Param name path string true "Package name"
+// @Param version path string true "Version"
+// @Param filename path string true "Filename"
+// @Param body body StartBlobUploadRequest false "Upload metadata"
+// @Security ApiKeyAuth
+// @Success 200 {object} StartBlobUploadResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /packages/{name}/versions/{version}/{filename}/resumable-uploads [post]
+func (h *Handler) StartBlobUpload(c *gin.Context) {
+	name := c.Param("name")
+	version := c.Param("version")
+	filename := c.Param("filename")
+
+	pkg, err := h.db.GetPackage(c.Request.Context(), name)
+	if err != nil {
+		h.logger.Error("Failed to get package", "error", err, "name", name)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to retrieve package"})
+		return
+	}
+	if pkg == nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Package not found"})
+		return
+	}
+
+	// The body is optional; an empty or absent one just means no explicit
+	// content type.
+	var req StartBlobUploadRequest
+	_ = c.ShouldBindJSON(&req)
+
+	state, err := blobupload.NewHasherState()
+	if err != nil {
+		h.logger.Error("Failed to start upload session", "error", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to start upload"})
+		return
+	}
+
+	sessionID := blobupload.NewSessionID()
+	ttl := time.Duration(h.config().Uploads.SessionTTLHour) * time.Hour
+	if _, err := h.db.StartBlobUpload(c.Request.Context(), sessionID, pkg.ID, version, filename, req.ContentType, state, time.Now().Add(ttl)); err != nil {
+		h.logger.Error("Failed to start upload session", "error", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to start upload"})
+		return
+	}
+
+	c.JSON(http.StatusOK, StartBlobUploadResponse{UploadID: sessionID})
+}
+
+// @Summary Append to a resumable upload
+// @Description Append the next range of bytes to an in-progress upload session
+// @Tags packages
+// @Accept octet-stream
+// @Produce json
+// @Param name path string true "Package name"
+// @Param version path string true "Version"
+// @Param filename path string true "Filename"
+// @Param uploadId path string true "Upload ID"
+// @Param Content-Range header string true "Byte range of this request, e.g. bytes 0-1048575/*"
+// @Security ApiKeyAuth
+// @Success 202 {object} BlobUploadStatusResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 416 {object} ErrorResponse
+// @Router /packages/{name}/versions/{version}/{filename}/resumable-uploads/{uploadId} [patch]
+func (h *Handler) AppendBlobUpload(c *gin.Context) {
+	sessionID := c.Param("uploadId")
+
+	session, err := h.db.GetBlobUpload(c.Request.Context(), sessionID)
+	if err != nil {
+		h.logger.Error("Failed to get upload session", "error", err, "session", sessionID)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to retrieve upload session"})
+		return
+	}
+	if session == nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Upload session not found"})
+		return
+	}
+
+	start, ok := contentRangeStart(c.GetHeader("Content-Range"))
+	if ok && start != session.ReceivedBytes {
+		c.Header("Range", fmt.Sprintf("bytes=0-%d", session.ReceivedBytes-1))
+		c.JSON(http.StatusRequestedRangeNotSatisfiable, ErrorResponse{Error: "Upload out of sync with session offset"})
+		return
+	}
+
+	hasher, err := blobupload.RestoreHasher(session.Sha256State)
+	if err != nil {
+		h.logger.Error("Failed to restore upload session", "error", err, "session", sessionID)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to resume upload session"})
+		return
+	}
+
+	chunks, err := chunkstore.Split(c.Request.Context(), h.storage, io.TeeReader(c.Request.Body, hasher))
+	if err != nil {
+		h.logger.Error("Failed to store uploaded chunk", "error", err, "session", sessionID)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to store uploaded bytes"})
+		return
+	}
+
+	var addedBytes int64
+	hashes := make([]string, len(chunks))
+	for i, chunk := range chunks {
+		hashes[i] = chunk.Hash
+		addedBytes += chunk.Size
+	}
+
+	state, err := blobupload.SaveHasherState(hasher)
+	if err != nil {
+		h.logger.Error("Failed to save upload session", "error", err, "session", sessionID)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to save upload progress"})
+		return
+	}
+
+	session, err = h.db.AppendBlobChunk(c.Request.Context(), sessionID, hashes, addedBytes, state)
+	if err != nil {
+		h.logger.Error("Failed to record upload progress", "error", err, "session", sessionID)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to record upload progress"})
+		return
+	}
+	if session == nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Upload session not found"})
+		return
+	}
+
+	c.Header("Range", fmt.Sprintf("bytes=0-%d", session.ReceivedBytes-1))
+	c.JSON(http.StatusAccepted, BlobUploadStatusResponse{UploadID: sessionID, ReceivedBytes: session.ReceivedBytes, ExpiresAt: session.ExpiresAt})
+}
+
+// @Summary Resume a resumable upload
+// @Description Return the current byte offset of an in-progress upload session
+// @Tags packages
+// @Produce json
+// @Param name path string true "Package name"
+// @Param version path string true "Version"
+// @Param filename path string true "Filename"
+// @Param uploadId path string true "Upload ID"
+// @Security ApiKeyAuth
+// @Success 200 {object} BlobUploadStatusResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /packages/{name}/versions/{version}/{filename}/resumable-uploads/{uploadId} [get]
+func (h *Handler) GetBlobUploadStatus(c *gin.Context) {
+	sessionID := c.Param("uploadId")
+
+	session, err := h.db.GetBlobUpload(c.Request.Context(), sessionID)
+	if err != nil {
+		h.logger.Error("Failed to get upload session", "error", err, "session", sessionID)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to retrieve upload session"})
+		return
+	}
+	if session == nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Upload session not found"})
+		return
+	}
+
+	c.Header("Range", fmt.Sprintf("bytes=0-%d", session.ReceivedBytes-1))
+	c.JSON(http.StatusOK, BlobUploadStatusResponse{UploadID: sessionID, ReceivedBytes: session.ReceivedBytes, ExpiresAt: session.ExpiresAt})
+}
+
+// @Summary Finish a resumable upload
+// @Description Verify and record the file reassembled from a finished upload session
+// @Tags packages
+// @Accept json
+// @Produce json
+// @Param name path string true "Package name"
+// @Param version path string true "Version"
+// @Param filename path string true "Filename"
+// @Param uploadId path string true "Upload ID"
+// @Param body body FinishBlobUploadRequest true "Expected digest"
+// @Security ApiKeyAuth
+// @Success 200 {object} UploadResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /packages/{name}/versions/{version}/{filename}/resumable-uploads/{uploadId} [put]
+func (h *Handler) FinishBlobUpload(c *gin.Context) {
+	name := c.Param("name")
+	version := c.Param("version")
+	sessionID := c.Param("uploadId")
+
+	var req FinishBlobUploadRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid request"})
+		return
+	}
+
+	session, err := h.db.GetBlobUpload(c.Request.Context(), sessionID)
+	if err != nil {
+		h.logger.Error("Failed to get upload session", "error", err, "session", sessionID)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to retrieve upload session"})
+		return
+	}
+	if session == nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Upload session not found"})
+		return
+	}
+
+	hasher, err := blobupload.RestoreHasher(session.Sha256State)
+	if err != nil {
+		h.logger.Error("Failed to restore upload session", "error", err, "session", sessionID)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to finish upload session"})
+		return
+	}
+	gotDigest := fmt.Sprintf("%x", hasher.Sum(nil))
+	if gotDigest != req.Sha256 {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Upload failed digest verification"})
+		return
+	}
+
+	pkg, err := h.db.GetPackage(c.Request.Context(), name)
+	if err != nil {
+		h.logger.Error("Failed to get package", "error", err, "name", name)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to retrieve package"})
+		return
+	}
+	if pkg == nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Package not found"})
+		return
+	}
+
+	// Claim the session before touching the chunk ref counts, so a
+	// concurrent retry of this same Finish call can't retain the chunks
+	// twice.
+	session, err = h.db.FinishBlobUpload(c.Request.Context(), sessionID)
+	if err != nil {
+		h.logger.Error("Failed to finish upload session", "error", err, "session", sessionID)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to finish upload"})
+		return
+	}
+	if session == nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Upload session not found"})
+		return
+	}
+
+	chunkSizes := make(map[string]int64, len(session.ChunkHashes))
+	for _, hash := range session.ChunkHashes {
+		size, err := h.storage.Size(c.Request.Context(), chunkstore.ChunkKey(hash))
+		if err != nil {
+			h.logger.Error("Failed to size uploaded chunk", "error", err, "hash", hash)
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to record uploaded file"})
+			return
+		}
+		chunkSizes[hash] = size
+	}
+	if err := h.db.RetainChunks(c.Request.Context(), chunkSizes); err != nil {
+		h.logger.Error("Failed to retain uploaded chunks", "error", err, "session", sessionID)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to record uploaded file"})
+		return
+	}
+
+	fileModel := &models.File{
+		Name:        session.Filename,
+		Size:        session.ReceivedBytes,
+		Hash:        gotDigest,
+		Hashes:      map[string]string{"sha256": gotDigest},
+		Chunks:      session.ChunkHashes,
+		ContentType: session.ContentType,
+		UploadedAt:  time.Now(),
+	}
+
+	ver, err := h.db.GetVersion(c.Request.Context(), pkg.ID, version)
+	if err != nil {
+		h.logger.Error("Failed to get version", "error", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to check version"})
+		return
+	}
+	if ver == nil {
+		ver = &models.Version{
+			PackageID:       pkg.ID,
+			Version:         version,
+			Files:           []models.File{*fileModel},
+			ProcessingState: models.ProcessingStatePending,
+		}
+		if err := h.db.CreateVersion(c.Request.Context(), ver, actor(c)); err != nil {
+			h.logger.Error("Failed to create version", "error", err)
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to create version"})
+			return
+		}
+	} else {
+		if err := h.db.AddFileToVersion(c.Request.Context(), pkg.ID, version, *fileModel, actor(c)); err != nil {
+			h.logger.Error("Failed to add file to version", "error", err)
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to add file to version"})
+			return
+		}
+		if err := h.db.SetVersionProcessingState(c.Request.Context(), pkg.ID, version, models.ProcessingStatePending, ""); err != nil {
+			h.logger.Error("Failed to reset processing state", "error", err)
+		}
+	}
+
+	if err := h.tasks.EnqueueProcessPackage(c.Request.Context(), worker.ProcessPackagePayload{
+		PackageID:   pkg.ID.Hex(),
+		PackageName: pkg.Name,
+		Version:     version,
+		FileKey:     chunkstore.ChunkKey(gotDigest),
+		Filename:    fileModel.Name,
+		Chunks:      fileModel.Chunks,
+	}); err != nil {
+		h.logger.Error("Failed to enqueue package processing", "error", err)
+	}
+
+	c.JSON(http.StatusOK, UploadResponse{
+		Message: "Upload completed, processing started",
+		File:    fileModel,
+	})
+}
+
+// contentRangeStart parses the start offset out of a "bytes start-end/total"
+// or "bytes start-end/*" Content-Range header. ok is false if the header is
+// absent or malformed, in which case the caller skips the offset check
+// rather than rejecting a client that didn't send one.
+func contentRangeStart(headerValue string) (start int64, ok bool) {
+	var end int64
+	var total string
+	if _, err := fmt.Sscanf(headerValue, "bytes %d-%d/%s", &start, &end, &total); err != nil {
+		return 0, false
+	}
+	return start, true
+}
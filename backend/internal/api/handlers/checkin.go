@@ -0,0 +1,326 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"net/http"
+
+	"github.com/Parallels/pd-ai-agents-registry/internal/models"
+	"github.com/Parallels/pd-ai-agents-registry/internal/semver"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// CheckInRequest is what a running client reports on every check-in.
+// GroupID assigns the instance to a release channel (stable/beta/nightly),
+// the same channels UploadUpdate publishes to; it defaults to stable.
+type CheckInRequest struct {
+	InstanceID     string `json:"instance_id" binding:"required"`
+	CurrentVersion string `json:"current_version" binding:"required"`
+	Platform       string `json:"platform" binding:"required"`
+	Arch           string `json:"arch" binding:"required"`
+	GroupID        string `json:"group_id"`
+}
+
+// RolloutPercentageRequest sets how many of a channel/platform/arch's
+// checked-in instances are eligible for the current latest version.
+type RolloutPercentageRequest struct {
+	Percentage int `json:"percentage" binding:"required,min=0,max=100"`
+}
+
+// RolloutBlacklistRequest blocks (or un-blocks) instances currently running
+// a specific version from being offered an update on check-in.
+type RolloutBlacklistRequest struct {
+	Version string `json:"version" binding:"required"`
+}
+
+// CheckIn handles an Omaha-style client check-in: it records the instance's
+// current state and tells it whether an update is available and it's
+// eligible to receive it.
+// @Summary Client check-in
+// @Description Report an instance's current version and find out whether an update is available
+// @Tags updates
+// @Accept json
+// @Produce json
+// @Param body body CheckInRequest true "Check-in payload"
+// @Success 200 {object} models.LatestVersionPlatform
+// @Success 204 "Up to date, or not eligible for the current rollout"
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/updates/check [post]
+func (h *Handler) CheckIn(c *gin.Context) {
+	var req CheckInRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid request"})
+		return
+	}
+
+	if !isValidPlatform(req.Platform) {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid platform. Must be one of: windows, darwin, linux"})
+		return
+	}
+	if !isValidArch(req.Arch) {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid arch. Must be one of: x86_64, i686, armv7, aarch64"})
+		return
+	}
+
+	channel := req.GroupID
+	if channel == "" {
+		channel = channelStable
+	}
+	if !isValidChannel(channel) {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid group_id. Must be one of: stable, beta, nightly"})
+		return
+	}
+
+	currentVersion, err := semver.Parse(req.CurrentVersion)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: fmt.Sprintf("Invalid current_version: %v", err)})
+		return
+	}
+
+	if err := h.db.UpsertInstance(c.Request.Context(), &models.Instance{
+		InstanceID:     req.InstanceID,
+		GroupID:        req.GroupID,
+		Channel:        channel,
+		Platform:       req.Platform,
+		Arch:           req.Arch,
+		CurrentVersion: req.CurrentVersion,
+		LastSeenIP:     c.ClientIP(),
+	}); err != nil {
+		h.logger.Error("Failed to record instance check-in", "error", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to record check-in"})
+		return
+	}
+
+	var latestVersion models.LatestVersion
+	err = h.db.Database().Collection("latest_version").FindOne(c.Request.Context(), bson.M{"channel": channel}).Decode(&latestVersion)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.Status(http.StatusNoContent)
+			return
+		}
+		h.logger.Error("Failed to find latest version for check-in", "error", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to check for updates"})
+		return
+	}
+
+	platformInfo, ok := latestVersion.Platforms[fmt.Sprintf("%s-%s", req.Platform, req.Arch)]
+	if !ok || platformInfo.URL == "" {
+		c.Status(http.StatusNoContent)
+		return
+	}
+
+	latest, err := semver.Parse(latestVersion.Version)
+	if err != nil || latest.Compare(currentVersion) <= 0 {
+		c.Status(http.StatusNoContent)
+		return
+	}
+
+	rollout, err := h.db.GetRollout(c.Request.Context(), channel, req.Platform, req.Arch)
+	if err != nil {
+		h.logger.Error("Failed to load rollout", "error", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to check for updates"})
+		return
+	}
+
+	if rollout != nil {
+		for _, blacklisted := range rollout.Blacklist {
+			if blacklisted == req.CurrentVersion {
+				c.Status(http.StatusNoContent)
+				return
+			}
+		}
+	}
+
+	if !rolloutEligible(req.InstanceID, rollout) {
+		c.Status(http.StatusNoContent)
+		return
+	}
+
+	platformInfo.URL = fmt.Sprintf("%s%s", h.config().GetBaseURL(), platformInfo.URL)
+	c.JSON(http.StatusOK, platformInfo)
+}
+
+// rolloutEligible reports whether instanceID falls within rollout's
+// percentage. A nil rollout (none configured yet) means fully rolled out, so
+// publishing an update works the same as before rollouts existed unless an
+// operator explicitly dials it back.
+func rolloutEligible(instanceID string, rollout *models.Rollout) bool {
+	if rollout == nil {
+		return true
+	}
+	return rolloutBucket(instanceID) < rollout.Percentage
+}
+
+// rolloutBucket hashes instanceID into a stable 0-99 bucket, so a given
+// instance's eligibility doesn't flap across check-ins as the percentage is
+// held constant.
+func rolloutBucket(instanceID string) int {
+	sum := sha256.Sum256([]byte(instanceID))
+	return int(binary.BigEndian.Uint32(sum[:4]) % 100)
+}
+
+// SetRolloutPercentage sets how many checked-in instances of a
+// channel/platform/arch are eligible for the current latest version.
+// @Summary Set rollout percentage
+// @Description Set the rollout percentage for a channel/platform/arch (admin only)
+// @Tags updates
+// @Accept json
+// @Produce json
+// @Param channel path string true "Release channel (stable, beta, nightly)"
+// @Param platform path string true "Platform (windows, darwin, linux)"
+// @Param arch path string true "Architecture (x86_64, i686, armv7, aarch64)"
+// @Param body body RolloutPercentageRequest true "Rollout percentage"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/updates/rollout/{channel}/{platform}/{arch} [post]
+// @Security ApiKeyAuth
+func (h *Handler) SetRolloutPercentage(c *gin.Context) {
+	channel := c.Param("channel")
+	platform := c.Param("platform")
+	arch := c.Param("arch")
+
+	if !isValidChannel(channel) {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid channel. Must be one of: stable, beta, nightly"})
+		return
+	}
+	if !isValidPlatform(platform) {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid platform. Must be one of: windows, darwin, linux"})
+		return
+	}
+	if !isValidArch(arch) {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid arch. Must be one of: x86_64, i686, armv7, aarch64"})
+		return
+	}
+
+	var req RolloutPercentageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid request"})
+		return
+	}
+
+	if err := h.db.SetRolloutPercentage(c.Request.Context(), channel, platform, arch, req.Percentage); err != nil {
+		h.logger.Error("Failed to set rollout percentage", "error", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to set rollout percentage"})
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{Message: "Rollout percentage updated"})
+}
+
+// AddRolloutBlacklist blocks instances currently running a specific version
+// from being offered an update for a channel/platform/arch.
+// @Summary Blacklist a version
+// @Description Block instances currently running a version from being offered an update for a channel/platform/arch (admin only)
+// @Tags updates
+// @Accept json
+// @Produce json
+// @Param channel path string true "Release channel (stable, beta, nightly)"
+// @Param platform path string true "Platform (windows, darwin, linux)"
+// @Param arch path string true "Architecture (x86_64, i686, armv7, aarch64)"
+// @Param body body RolloutBlacklistRequest true "Version to blacklist"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/updates/rollout/{channel}/{platform}/{arch}/blacklist [post]
+// @Security ApiKeyAuth
+func (h *Handler) AddRolloutBlacklist(c *gin.Context) {
+	channel := c.Param("channel")
+	platform := c.Param("platform")
+	arch := c.Param("arch")
+
+	if !isValidChannel(channel) {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid channel. Must be one of: stable, beta, nightly"})
+		return
+	}
+	if !isValidPlatform(platform) {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid platform. Must be one of: windows, darwin, linux"})
+		return
+	}
+	if !isValidArch(arch) {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid arch. Must be one of: x86_64, i686, armv7, aarch64"})
+		return
+	}
+
+	var req RolloutBlacklistRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid request"})
+		return
+	}
+
+	if err := h.db.AddBlacklistedVersion(c.Request.Context(), channel, platform, arch, req.Version); err != nil {
+		h.logger.Error("Failed to blacklist version", "error", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to blacklist version"})
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{Message: "Version blacklisted"})
+}
+
+// RemoveRolloutBlacklist un-blocks a previously blacklisted version for a
+// channel/platform/arch.
+// @Summary Un-blacklist a version
+// @Description Allow instances running a previously blacklisted version to be offered updates again for a channel/platform/arch (admin only)
+// @Tags updates
+// @Produce json
+// @Param channel path string true "Release channel (stable, beta, nightly)"
+// @Param platform path string true "Platform (windows, darwin, linux)"
+// @Param arch path string true "Architecture (x86_64, i686, armv7, aarch64)"
+// @Param version path string true "Version to un-blacklist"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/updates/rollout/{channel}/{platform}/{arch}/blacklist/{version} [delete]
+// @Security ApiKeyAuth
+func (h *Handler) RemoveRolloutBlacklist(c *gin.Context) {
+	channel := c.Param("channel")
+	platform := c.Param("platform")
+	arch := c.Param("arch")
+	version := c.Param("version")
+
+	if !isValidChannel(channel) {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid channel. Must be one of: stable, beta, nightly"})
+		return
+	}
+	if !isValidPlatform(platform) {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid platform. Must be one of: windows, darwin, linux"})
+		return
+	}
+	if !isValidArch(arch) {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid arch. Must be one of: x86_64, i686, armv7, aarch64"})
+		return
+	}
+
+	if err := h.db.RemoveBlacklistedVersion(c.Request.Context(), channel, platform, arch, version); err != nil {
+		h.logger.Error("Failed to un-blacklist version", "error", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to un-blacklist version"})
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{Message: "Version un-blacklisted"})
+}
+
+// InstanceStats returns how many checked-in instances are reporting each
+// version, for update-adoption observability.
+// @Summary Instance version stats
+// @Description Get a breakdown of checked-in instances by reported version (admin only)
+// @Tags updates
+// @Produce json
+// @Success 200 {array} mongodb.VersionCount
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/updates/stats [get]
+// @Security ApiKeyAuth
+func (h *Handler) InstanceStats(c *gin.Context) {
+	stats, err := h.db.InstanceStats(c.Request.Context())
+	if err != nil {
+		h.logger.Error("Failed to get instance stats", "error", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to get instance stats"})
+		return
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
@@ -1,11 +1,17 @@
 package handlers
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"time"
 
+	"github.com/Parallels/pd-ai-agents-registry/internal/chunkstore"
 	"github.com/Parallels/pd-ai-agents-registry/internal/models"
+	"github.com/Parallels/pd-ai-agents-registry/internal/storage"
 	"github.com/gin-gonic/gin"
 )
 
@@ -15,7 +21,7 @@ import (
 // @Accept json
 // @Produce octet-stream
 // @Param name path string true "Package name"
-// @Param version path string true "Version"
+// @Param version path string true "Version, dist-tag, or semver constraint"
 // @Param filename path string true "Filename"
 // @Success 200 {file} binary
 // @Failure 404 {object} ErrorResponse
@@ -23,25 +29,15 @@ import (
 // @Router /download/{name}/{version}/{filename} [get]
 func (h *Handler) DownloadPackage(c *gin.Context) {
 	name := c.Param("name")
-	version := c.Param("version")
+	spec := c.Param("version")
 	filename := c.Param("filename")
 
-	// Get package
-	pkg, err := h.db.GetPackage(c.Request.Context(), name)
+	// Resolve the version path segment, which may be an exact version, a
+	// dist-tag, or a semver constraint, against the package's actual stored
+	// version before building the storage key below.
+	ver, err := h.db.ResolveVersion(c.Request.Context(), name, spec)
 	if err != nil {
-		h.logger.Error("Failed to get package", "error", err)
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to retrieve package"})
-		return
-	}
-	if pkg == nil {
-		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Package not found"})
-		return
-	}
-
-	// Get version
-	ver, err := h.db.GetVersion(c.Request.Context(), pkg.ID, version)
-	if err != nil {
-		h.logger.Error("Failed to get version", "error", err)
+		h.logger.Error("Failed to resolve version", "error", err, "package", name, "version", spec)
 		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to retrieve version"})
 		return
 	}
@@ -49,6 +45,7 @@ func (h *Handler) DownloadPackage(c *gin.Context) {
 		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Version not found"})
 		return
 	}
+	version := ver.Version
 
 	// Find file in version
 	var fileInfo *models.File
@@ -63,29 +60,113 @@ func (h *Handler) DownloadPackage(c *gin.Context) {
 		return
 	}
 
-	// Generate S3 key
-	s3Key := fmt.Sprintf("packages/%s/%s/%s", name, version, filename)
+	var reader io.ReadCloser
+	if len(fileInfo.Chunks) > 0 {
+		// Chunked files have no single blob to presign or existence-check;
+		// reassembly fetches each chunk lazily as it's read.
+		reader, err = chunkstore.Open(c.Request.Context(), h.storage, fileInfo.Chunks)
+		if err != nil {
+			h.logger.Error("Failed to open chunked file", "error", err)
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to open file"})
+			return
+		}
+	} else {
+		// Generate storage key
+		key := fmt.Sprintf("packages/%s/%s/%s", name, version, filename)
 
-	// Check if file exists in S3
-	exists, err := h.storage.Exists(c.Request.Context(), s3Key)
-	if err != nil {
-		h.logger.Error("Failed to check file existence", "error", err)
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to check file existence"})
+		exists, err := h.storage.Exists(c.Request.Context(), key)
+		if err != nil {
+			h.logger.Error("Failed to check file existence", "error", err)
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to check file existence"})
+			return
+		}
+		if !exists {
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: "File not found in storage"})
+			return
+		}
+
+		// Backends that record their own integrity tag (currently just S3)
+		// let us catch storage-side corruption before paying for a
+		// presigned redirect or a full download; the re-hash below still
+		// runs afterward as the authoritative check against MongoDB.
+		if tagger, ok := h.storage.(storage.IntegrityTagger); ok {
+			wantTag := fileInfo.Hashes["sha256"]
+			if wantTag == "" {
+				wantTag = fileInfo.Hash
+			}
+			if gotTag, err := tagger.GetIntegrityTag(c.Request.Context(), key); err != nil {
+				h.logger.Error("Failed to read storage integrity tag", "error", err, "key", key)
+			} else if wantTag != "" && gotTag != "" && gotTag != wantTag {
+				h.logger.Error("Storage-tagged checksum mismatch, corruption suspected", "package", name, "version", version, "filename", filename, "want_hash", wantTag, "got_tag", gotTag)
+				c.JSON(http.StatusBadGateway, ErrorResponse{Error: "File failed storage integrity check"})
+				return
+			}
+		}
+
+		reader, err = h.storage.Get(c.Request.Context(), key)
+		if err != nil {
+			h.logger.Error("Failed to open file", "error", err)
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to open file"})
+			return
+		}
+	}
+	defer reader.Close()
+
+	// A presigned URL is generated (but not yet handed to the client) up
+	// front, so we know below whether this download will end in a redirect
+	// or in the API serving the bytes itself. Generating it is a metadata
+	// call, not a data transfer, so doing it before verification is safe;
+	// only *redirecting* before the re-hash below would let a corrupted
+	// object be served straight from storage.
+	var signedURL string
+	if len(fileInfo.Chunks) == 0 {
+		key := fmt.Sprintf("packages/%s/%s/%s", name, version, filename)
+		url, err := h.storage.PresignGet(c.Request.Context(), key, 15*time.Minute)
+		switch {
+		case err == nil:
+			signedURL = url
+		case errors.Is(err, storage.ErrPresignNotSupported):
+			// No presigning available; the bytes will be served directly below.
+		default:
+			h.logger.Error("Failed to generate signed URL", "error", err)
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to generate download URL"})
+			return
+		}
+	}
+
+	// Re-hash the bytes as they're read so bit rot or tampering in storage
+	// is caught before the file is served, on every download, including
+	// ones the backend can presign. When the download will end in a
+	// redirect, the bytes are hashed and discarded rather than buffered:
+	// the client fetches them again straight from storage, so holding a
+	// second copy in API server memory would risk OOMing on exactly the
+	// multi-GB files presigning exists to take off the server's plate.
+	wantHash := fileInfo.Hashes["sha256"]
+	if wantHash == "" {
+		wantHash = fileInfo.Hash
+	}
+	hasher := sha256.New()
+	var body bytes.Buffer
+	var sink io.Writer = &body
+	if signedURL != "" {
+		sink = io.Discard
+	}
+	if _, err := io.Copy(sink, io.TeeReader(reader, hasher)); err != nil {
+		h.logger.Error("Failed to read file", "error", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to read file"})
 		return
 	}
-	if !exists {
-		c.JSON(http.StatusNotFound, ErrorResponse{Error: "File not found in storage"})
+	if gotHash := fmt.Sprintf("%x", hasher.Sum(nil)); wantHash != "" && gotHash != wantHash {
+		h.logger.Error("Stored file failed integrity check, quarantining", "package", name, "version", version, "filename", filename, "want_hash", wantHash, "got_hash", gotHash)
+		c.JSON(http.StatusGone, ErrorResponse{Error: "File failed integrity verification"})
 		return
 	}
 
-	// Get signed URL for download
-	signedURL, err := h.storage.GetSignedURL(c.Request.Context(), s3Key, 15*time.Minute)
-	if err != nil {
-		h.logger.Error("Failed to generate signed URL", "error", err)
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to generate download URL"})
+	if signedURL != "" {
+		c.Redirect(http.StatusTemporaryRedirect, signedURL)
 		return
 	}
 
-	// Redirect to signed URL
-	c.Redirect(http.StatusTemporaryRedirect, signedURL)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s", filename))
+	c.Data(http.StatusOK, "application/octet-stream", body.Bytes())
 }
@@ -3,55 +3,63 @@ package handlers
 import (
 	"context"
 	"crypto/sha256"
+	"crypto/sha512"
 	"fmt"
 	"io"
 	"mime/multipart"
-	"path"
 	"time"
 
+	"github.com/Parallels/pd-ai-agents-registry/internal/chunkstore"
 	"github.com/Parallels/pd-ai-agents-registry/internal/models"
+	"golang.org/x/crypto/blake2b"
 )
 
+// processUploadedFile splits the upload into content-defined chunks (so
+// identical blocks shared across versions/packages are stored once),
+// hashing it for integrity verification as it goes.
 func (h *Handler) processUploadedFile(ctx context.Context, file *multipart.FileHeader) (*models.File, error) {
-	// Open the file
 	src, err := file.Open()
 	if err != nil {
 		return nil, err
 	}
 	defer src.Close()
 
-	// Calculate hash
-	hash := sha256.New()
-	if _, err := io.Copy(hash, src); err != nil {
+	sha256Hash := sha256.New()
+	sha512Hash := sha512.New()
+	blake2bHash, err := blake2b.New256(nil)
+	if err != nil {
 		return nil, err
 	}
-	fileHash := fmt.Sprintf("%x", hash.Sum(nil))
+	tee := io.TeeReader(src, io.MultiWriter(sha256Hash, sha512Hash, blake2bHash))
 
-	// Reset file pointer
-	if _, err := src.Seek(0, 0); err != nil {
+	chunks, err := chunkstore.Split(ctx, h.storage, tee)
+	if err != nil {
 		return nil, err
 	}
 
-	// Generate S3 key
-	s3Key := path.Join("packages", fileHash, file.Filename)
-
-	// Upload to S3
-	if err := h.storage.Upload(ctx, s3Key, src); err != nil {
-		return nil, err
+	chunkHashes := make([]string, len(chunks))
+	chunkSizes := make(map[string]int64, len(chunks))
+	for i, c := range chunks {
+		chunkHashes[i] = c.Hash
+		chunkSizes[c.Hash] = c.Size
+	}
+	if err := h.db.RetainChunks(ctx, chunkSizes); err != nil {
+		return nil, fmt.Errorf("failed to retain chunks: %w", err)
 	}
 
-	// Generate download URL
-	downloadURL, err := h.storage.GetSignedURL(ctx, s3Key, 24*time.Hour)
-	if err != nil {
-		return nil, err
+	hashes := map[string]string{
+		"sha256":  fmt.Sprintf("%x", sha256Hash.Sum(nil)),
+		"sha512":  fmt.Sprintf("%x", sha512Hash.Sum(nil)),
+		"blake2b": fmt.Sprintf("%x", blake2bHash.Sum(nil)),
 	}
 
 	return &models.File{
 		Name:        file.Filename,
 		Size:        file.Size,
-		Hash:        fileHash,
+		Hash:        hashes["sha256"],
+		Hashes:      hashes,
+		Chunks:      chunkHashes,
 		ContentType: file.Header.Get("Content-Type"),
-		DownloadURL: downloadURL,
 		UploadedAt:  time.Now(),
 	}, nil
 }
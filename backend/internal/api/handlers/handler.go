@@ -1,30 +1,99 @@
 package handlers
 
 import (
+	"crypto/rsa"
+	"sync/atomic"
+
+	"github.com/Parallels/pd-ai-agents-registry/internal/auth"
 	"github.com/Parallels/pd-ai-agents-registry/internal/config"
 	"github.com/Parallels/pd-ai-agents-registry/internal/db/mongodb"
 	"github.com/Parallels/pd-ai-agents-registry/internal/logger"
-	"github.com/Parallels/pd-ai-agents-registry/internal/services/storage"
+	"github.com/Parallels/pd-ai-agents-registry/internal/registry"
+	"github.com/Parallels/pd-ai-agents-registry/internal/signing"
+	"github.com/Parallels/pd-ai-agents-registry/internal/storage"
+	"github.com/Parallels/pd-ai-agents-registry/internal/worker"
+	"github.com/gin-gonic/gin"
 )
 
 type Handler struct {
-	cfg     *config.Config
-	logger  *logger.Logger
-	db      *mongodb.Client
-	storage *storage.S3Service
+	cfg           atomic.Pointer[config.Config]
+	logger        *logger.Logger
+	db            *mongodb.CachingClient
+	storage       storage.Backend
+	tasks         *worker.Client
+	signing       *signing.Verifier
+	tokens        *auth.Issuer
+	apkSigningKey *rsa.PrivateKey
 }
 
-func NewHandler(cfg *config.Config, logger *logger.Logger, db *mongodb.Client) (*Handler, error) {
-	// Initialize S3 storage service
-	s3Service, err := storage.NewS3Service(cfg.S3)
+func NewHandler(cfg *config.Config, logger *logger.Logger, db *mongodb.CachingClient) (*Handler, error) {
+	backend, err := storage.New(cfg.Storage)
+	if err != nil {
+		return nil, err
+	}
+
+	verifier, err := signing.NewVerifier(cfg.Signing.TrustedKeys)
 	if err != nil {
 		return nil, err
 	}
 
-	return &Handler{
-		cfg:     cfg,
-		logger:  logger,
-		db:      db,
-		storage: s3Service,
-	}, nil
+	issuer, err := auth.NewIssuer(cfg.JWT)
+	if err != nil {
+		return nil, err
+	}
+
+	// Unset by default: a deployment that never configures it simply can't
+	// serve the apk repository format (see registry.Generate).
+	var apkSigningKey *rsa.PrivateKey
+	if cfg.Registry.APKSigningKeyPEM != "" {
+		apkSigningKey, err = registry.ParseAPKSigningKey([]byte(cfg.Registry.APKSigningKeyPEM))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	h := &Handler{
+		logger:        logger,
+		db:            db,
+		storage:       backend,
+		tasks:         worker.NewClient(cfg.Redis),
+		signing:       verifier,
+		tokens:        issuer,
+		apkSigningKey: apkSigningKey,
+	}
+	h.cfg.Store(cfg)
+	return h, nil
+}
+
+// Tokens exposes the handler's JWT issuer so the router can hand it to the
+// auth middleware for JWT verification and JTI denylist checks.
+func (h *Handler) Tokens() *auth.Issuer {
+	return h.tokens
+}
+
+// config returns the most recently loaded Config. Reads through this
+// accessor (rather than a plain field) are safe against SetConfig running
+// concurrently from config.Watch's fsnotify goroutine.
+func (h *Handler) config() *config.Config {
+	return h.cfg.Load()
+}
+
+// SetConfig swaps in a newly loaded Config, e.g. after config.Watch detects
+// the config file changed. In-flight requests keep using whichever Config
+// they already loaded; only requests started afterward see the update.
+func (h *Handler) SetConfig(cfg *config.Config) {
+	h.cfg.Store(cfg)
+}
+
+// actor identifies the authenticated user for audit logging. Every route
+// that reaches a mutating Client method also requires auth.JWT(), so
+// "user_id" is always set; it falls back to "unknown" rather than panicking
+// in case that ever stops being true for some future route.
+func actor(c *gin.Context) string {
+	if userID, ok := c.Get("user_id"); ok {
+		if s, ok := userID.(string); ok {
+			return s
+		}
+	}
+	return "unknown"
 }
@@ -0,0 +1,264 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"path"
+	"strconv"
+	"time"
+
+	"github.com/Parallels/pd-ai-agents-registry/internal/models"
+	"github.com/Parallels/pd-ai-agents-registry/internal/storage"
+	"github.com/Parallels/pd-ai-agents-registry/internal/worker"
+	"github.com/gin-gonic/gin"
+)
+
+// partPresignTTL is how long a single-part upload URL stays valid. It only
+// needs to cover one PUT, but large parts over a slow link can take a
+// while, so it's generous compared to download presigning.
+const partPresignTTL = 1 * time.Hour
+
+// InitiateUploadRequest optionally tells the backend the file's final
+// content type, so it's set correctly on the object from the start instead
+// of needing a follow-up metadata update.
+type InitiateUploadRequest struct {
+	ContentType string `json:"content_type"`
+}
+
+// InitiateUploadResponse identifies a multipart upload the client can now
+// PUT parts against directly, bypassing the API server entirely.
+type InitiateUploadResponse struct {
+	UploadID string `json:"upload_id"`
+	Key      string `json:"key"`
+}
+
+// PresignPartResponse is a time-limited URL for uploading a single part.
+type PresignPartResponse struct {
+	URL string `json:"url"`
+}
+
+// CompletedPartRequest is one entry of the part list passed to
+// CompleteMultipartUpload, as reported back by the client.
+type CompletedPartRequest struct {
+	PartNumber int32  `json:"part_number" binding:"required"`
+	ETag       string `json:"etag" binding:"required"`
+}
+
+// CompleteUploadRequest finalizes a multipart upload. Sha256 is supplied by
+// the client, which already has the full file in hand; the server never
+// buffers the upload to compute it itself.
+type CompleteUploadRequest struct {
+	Parts       []CompletedPartRequest `json:"parts" binding:"required,min=1,dive"`
+	Size        int64                  `json:"size" binding:"required"`
+	ContentType string                 `json:"content_type"`
+	Sha256      string                 `json:"sha256"`
+}
+
+// multipartKey returns the storage key a direct-to-storage multipart
+// upload is addressed at, matching the legacy (non-chunked) download key
+// layout so the resulting file is downloadable the same way.
+func multipartKey(name, version, filename string) string {
+	return path.Join("packages", name, version, filename)
+}
+
+// @Summary Initiate a multipart upload
+// @Description Start a direct-to-storage multipart upload for a large package file
+// @Tags packages
+// @Accept json
+// @Produce json
+// @Param name path string true "Package name"
+// @Param version path string true "Version"
+// @Param filename path string true "Filename"
+// @Param body body InitiateUploadRequest false "Upload metadata"
+// @Security ApiKeyAuth
+// @Success 200 {object} InitiateUploadResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 501 {object} ErrorResponse
+// @Router /packages/{name}/versions/{version}/{filename}/uploads [post]
+func (h *Handler) InitiateMultipartUpload(c *gin.Context) {
+	name := c.Param("name")
+	version := c.Param("version")
+	filename := c.Param("filename")
+
+	mb, ok := h.storage.(storage.MultipartBackend)
+	if !ok {
+		c.JSON(http.StatusNotImplemented, ErrorResponse{Error: "Configured storage backend does not support multipart uploads"})
+		return
+	}
+
+	// The body is optional; an empty or absent one just means no explicit
+	// content type.
+	var req InitiateUploadRequest
+	_ = c.ShouldBindJSON(&req)
+
+	key := multipartKey(name, version, filename)
+	uploadID, err := mb.CreateMultipartUpload(c.Request.Context(), key, req.ContentType)
+	if err != nil {
+		h.logger.Error("Failed to create multipart upload", "error", err, "key", key)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to initiate upload"})
+		return
+	}
+
+	c.JSON(http.StatusOK, InitiateUploadResponse{UploadID: uploadID, Key: key})
+}
+
+// @Summary Presign a multipart upload part
+// @Description Return a URL the client can PUT a single part's bytes to directly
+// @Tags packages
+// @Produce json
+// @Param name path string true "Package name"
+// @Param version path string true "Version"
+// @Param filename path string true "Filename"
+// @Param uploadId path string true "Upload ID"
+// @Param partNumber path int true "Part number, 1-indexed"
+// @Security ApiKeyAuth
+// @Success 200 {object} PresignPartResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 501 {object} ErrorResponse
+// @Router /packages/{name}/versions/{version}/{filename}/uploads/{uploadId}/parts/{partNumber} [get]
+func (h *Handler) PresignUploadPart(c *gin.Context) {
+	name := c.Param("name")
+	version := c.Param("version")
+	filename := c.Param("filename")
+	uploadID := c.Param("uploadId")
+
+	partNumber, err := strconv.ParseInt(c.Param("partNumber"), 10, 32)
+	if err != nil || partNumber < 1 {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid part number"})
+		return
+	}
+
+	mb, ok := h.storage.(storage.MultipartBackend)
+	if !ok {
+		c.JSON(http.StatusNotImplemented, ErrorResponse{Error: "Configured storage backend does not support multipart uploads"})
+		return
+	}
+
+	key := multipartKey(name, version, filename)
+	url, err := mb.PresignUploadPart(c.Request.Context(), key, uploadID, int32(partNumber), partPresignTTL)
+	if err != nil {
+		h.logger.Error("Failed to presign upload part", "error", err, "key", key, "upload_id", uploadID)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to presign upload part"})
+		return
+	}
+
+	c.JSON(http.StatusOK, PresignPartResponse{URL: url})
+}
+
+// @Summary Complete a multipart upload
+// @Description Finalize a multipart upload and record the resulting file against the version
+// @Tags packages
+// @Accept json
+// @Produce json
+// @Param name path string true "Package name"
+// @Param version path string true "Version"
+// @Param filename path string true "Filename"
+// @Param uploadId path string true "Upload ID"
+// @Param body body CompleteUploadRequest true "Completed parts"
+// @Security ApiKeyAuth
+// @Success 200 {object} UploadResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 501 {object} ErrorResponse
+// @Router /packages/{name}/versions/{version}/{filename}/uploads/{uploadId}/complete [post]
+func (h *Handler) CompleteMultipartUpload(c *gin.Context) {
+	name := c.Param("name")
+	version := c.Param("version")
+	filename := c.Param("filename")
+	uploadID := c.Param("uploadId")
+
+	var req CompleteUploadRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid request"})
+		return
+	}
+
+	mb, ok := h.storage.(storage.MultipartBackend)
+	if !ok {
+		c.JSON(http.StatusNotImplemented, ErrorResponse{Error: "Configured storage backend does not support multipart uploads"})
+		return
+	}
+
+	pkg, err := h.db.GetPackage(c.Request.Context(), name)
+	if err != nil {
+		h.logger.Error("Failed to get package", "error", err, "name", name)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to retrieve package"})
+		return
+	}
+	if pkg == nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Package not found"})
+		return
+	}
+
+	key := multipartKey(name, version, filename)
+	parts := make([]storage.CompletedPart, len(req.Parts))
+	for i, p := range req.Parts {
+		parts[i] = storage.CompletedPart{PartNumber: p.PartNumber, ETag: p.ETag}
+	}
+
+	if _, _, err := mb.CompleteMultipartUpload(c.Request.Context(), key, uploadID, parts); err != nil {
+		h.logger.Error("Failed to complete multipart upload", "error", err, "key", key, "upload_id", uploadID)
+		if abortErr := mb.AbortMultipartUpload(c.Request.Context(), key, uploadID); abortErr != nil {
+			h.logger.Error("Failed to abort multipart upload", "error", abortErr, "key", key, "upload_id", uploadID)
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to complete upload"})
+		return
+	}
+
+	var hashes map[string]string
+	if req.Sha256 != "" {
+		hashes = map[string]string{"sha256": req.Sha256}
+	}
+	fileModel := &models.File{
+		Name:        filename,
+		Size:        req.Size,
+		Hash:        req.Sha256,
+		Hashes:      hashes,
+		ContentType: req.ContentType,
+		UploadedAt:  time.Now(),
+	}
+
+	ver, err := h.db.GetVersion(c.Request.Context(), pkg.ID, version)
+	if err != nil {
+		h.logger.Error("Failed to get version", "error", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to check version"})
+		return
+	}
+	if ver == nil {
+		ver = &models.Version{
+			PackageID:       pkg.ID,
+			Version:         version,
+			Files:           []models.File{*fileModel},
+			ProcessingState: models.ProcessingStatePending,
+		}
+		if err := h.db.CreateVersion(c.Request.Context(), ver, actor(c)); err != nil {
+			h.logger.Error("Failed to create version", "error", err)
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to create version"})
+			return
+		}
+	} else {
+		if err := h.db.AddFileToVersion(c.Request.Context(), pkg.ID, version, *fileModel, actor(c)); err != nil {
+			h.logger.Error("Failed to add file to version", "error", err)
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to add file to version"})
+			return
+		}
+		if err := h.db.SetVersionProcessingState(c.Request.Context(), pkg.ID, version, models.ProcessingStatePending, ""); err != nil {
+			h.logger.Error("Failed to reset processing state", "error", err)
+		}
+	}
+
+	if err := h.tasks.EnqueueProcessPackage(c.Request.Context(), worker.ProcessPackagePayload{
+		PackageID:   pkg.ID.Hex(),
+		PackageName: pkg.Name,
+		Version:     version,
+		FileKey:     key,
+		Filename:    filename,
+	}); err != nil {
+		h.logger.Error("Failed to enqueue package processing", "error", err)
+	}
+
+	c.JSON(http.StatusOK, UploadResponse{
+		Message: fmt.Sprintf("Upload completed, %d parts merged, processing started", len(req.Parts)),
+		File:    fileModel,
+	})
+}
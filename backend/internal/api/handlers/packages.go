@@ -2,28 +2,90 @@ package handlers
 
 import (
 	"net/http"
+	"path"
+	"strconv"
 
+	"github.com/Parallels/pd-ai-agents-registry/internal/chunkstore"
+	"github.com/Parallels/pd-ai-agents-registry/internal/db/mongodb"
 	"github.com/Parallels/pd-ai-agents-registry/internal/models"
+	"github.com/Parallels/pd-ai-agents-registry/internal/worker"
 	"github.com/gin-gonic/gin"
 )
 
 // @Summary List all packages
-// @Description Get a list of all available packages
+// @Description Get a paginated, filterable list of available packages
 // @Tags packages
 // @Accept json
 // @Produce json
+// @Param q query string false "Free-text search across name/description/categories"
+// @Param category query string false "Filter by category"
+// @Param author query string false "Filter by author"
+// @Param is_official query bool false "Filter by official status"
+// @Param sort query string false "name|created_at|star_rating"
+// @Param order query string false "asc|desc"
+// @Param page query int false "Page number, 1-indexed"
+// @Param per_page query int false "Items per page (max 100)"
 // @Security BearerAuth
-// @Success 200 {array} models.Package
+// @Success 200 {object} ListPackagesResponse
 // @Failure 500 {object} ErrorResponse
 // @Router /packages [get]
 func (h *Handler) ListPackages(c *gin.Context) {
-	packages, err := h.db.ListPackages(c.Request.Context())
+	filter := mongodb.ListPackagesFilter{
+		Query:    c.Query("q"),
+		Category: c.Query("category"),
+		Author:   c.Query("author"),
+		Sort:     c.Query("sort"),
+		Order:    c.Query("order"),
+	}
+	if raw := c.Query("is_official"); raw != "" {
+		if v, err := strconv.ParseBool(raw); err == nil {
+			filter.IsOfficial = &v
+		}
+	}
+	filter.Page = 1
+	if raw := c.Query("page"); raw != "" {
+		if v, err := strconv.Atoi(raw); err == nil && v > 0 {
+			filter.Page = v
+		}
+	}
+	filter.PerPage = 20
+	if raw := c.Query("per_page"); raw != "" {
+		if v, err := strconv.Atoi(raw); err == nil && v > 0 {
+			filter.PerPage = v
+		}
+	}
+
+	packages, total, err := h.db.ListPackages(c.Request.Context(), filter)
 	if err != nil {
 		h.logger.Error("Failed to list packages", "error", err)
 		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to retrieve packages"})
 		return
 	}
-	c.JSON(http.StatusOK, packages)
+
+	c.JSON(http.StatusOK, ListPackagesResponse{
+		Items:   packages,
+		Total:   total,
+		Page:    filter.Page,
+		PerPage: filter.PerPage,
+	})
+}
+
+// @Summary List package categories
+// @Description Get every distinct package category with its package count
+// @Tags packages
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} mongodb.CategoryCount
+// @Failure 500 {object} ErrorResponse
+// @Router /packages/categories [get]
+func (h *Handler) ListCategories(c *gin.Context) {
+	categories, err := h.db.ListCategories(c.Request.Context())
+	if err != nil {
+		h.logger.Error("Failed to list categories", "error", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to retrieve categories"})
+		return
+	}
+	c.JSON(http.StatusOK, categories)
 }
 
 // @Summary Get package details
@@ -58,6 +120,8 @@ func (h *Handler) GetPackage(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param name path string true "Package name"
+// @Param sort query string false "semver to order by parsed SemVer instead of insertion order"
+// @Param include_yanked query string false "true to include yanked versions, which are excluded by default"
 // @Security BearerAuth
 // @Success 200 {array} models.Version
 // @Failure 404 {object} ErrorResponse
@@ -78,7 +142,9 @@ func (h *Handler) ListVersions(c *gin.Context) {
 		return
 	}
 
-	versions, err := h.db.ListVersions(c.Request.Context(), pkg.ID)
+	sortSemver := c.Query("sort") == "semver"
+	includeYanked := c.Query("include_yanked") == "true"
+	versions, err := h.db.ListVersions(c.Request.Context(), pkg.ID, sortSemver, includeYanked)
 	if err != nil {
 		h.logger.Error("Failed to list versions", "error", err, "package", name)
 		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to retrieve versions"})
@@ -88,12 +154,14 @@ func (h *Handler) ListVersions(c *gin.Context) {
 }
 
 // @Summary Get version details
-// @Description Get details for a specific package version
+// @Description Get details for a specific package version. The version path
+// @Description segment accepts an exact version, a dist-tag (e.g. "latest"),
+// @Description or a semver constraint such as "^1.2.0".
 // @Tags packages
 // @Accept json
 // @Produce json
 // @Param name path string true "Package name"
-// @Param version path string true "Version"
+// @Param version path string true "Version, dist-tag, or semver constraint"
 // @Security BearerAuth
 // @Success 200 {object} models.Version
 // @Failure 404 {object} ErrorResponse
@@ -103,20 +171,9 @@ func (h *Handler) GetVersion(c *gin.Context) {
 	name := c.Param("name")
 	version := c.Param("version")
 
-	pkg, err := h.db.GetPackage(c.Request.Context(), name)
+	ver, err := h.db.ResolveVersion(c.Request.Context(), name, version)
 	if err != nil {
-		h.logger.Error("Failed to get package", "error", err, "name", name)
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to retrieve package"})
-		return
-	}
-	if pkg == nil {
-		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Package not found"})
-		return
-	}
-
-	ver, err := h.db.GetVersion(c.Request.Context(), pkg.ID, version)
-	if err != nil {
-		h.logger.Error("Failed to get version", "error", err, "package", name, "version", version)
+		h.logger.Error("Failed to resolve version", "error", err, "package", name, "version", version)
 		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to retrieve version"})
 		return
 	}
@@ -183,30 +240,101 @@ func (h *Handler) UploadPackage(c *gin.Context) {
 	if ver == nil {
 		// Create new version
 		ver = &models.Version{
-			PackageID: pkg.ID,
-			Version:   version,
-			Files:     []models.File{*fileModel},
+			PackageID:       pkg.ID,
+			Version:         version,
+			Files:           []models.File{*fileModel},
+			ProcessingState: models.ProcessingStatePending,
 		}
-		if err := h.db.CreateVersion(c.Request.Context(), ver); err != nil {
+		if err := h.db.CreateVersion(c.Request.Context(), ver, actor(c)); err != nil {
 			h.logger.Error("Failed to create version", "error", err)
 			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to create version"})
 			return
 		}
 	} else {
 		// Add file to existing version
-		if err := h.db.AddFileToVersion(c.Request.Context(), pkg.ID, version, *fileModel); err != nil {
+		if err := h.db.AddFileToVersion(c.Request.Context(), pkg.ID, version, *fileModel, actor(c)); err != nil {
 			h.logger.Error("Failed to add file to version", "error", err)
 			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to add file to version"})
 			return
 		}
+		if err := h.db.SetVersionProcessingState(c.Request.Context(), pkg.ID, version, models.ProcessingStatePending, ""); err != nil {
+			h.logger.Error("Failed to reset processing state", "error", err)
+		}
+	}
+
+	// Hashing, manifest validation, and forbidden-file scanning happen off
+	// the request path so a slow/failing check doesn't block the upload.
+	if err := h.tasks.EnqueueProcessPackage(c.Request.Context(), worker.ProcessPackagePayload{
+		PackageID:   pkg.ID.Hex(),
+		PackageName: pkg.Name,
+		Version:     version,
+		FileKey:     path.Join("packages", pkg.Name, version, fileModel.Name),
+		Filename:    fileModel.Name,
+		Chunks:      fileModel.Chunks,
+	}); err != nil {
+		h.logger.Error("Failed to enqueue package processing", "error", err)
 	}
 
 	c.JSON(http.StatusOK, UploadResponse{
-		Message: "File uploaded successfully",
+		Message: "File uploaded successfully, processing started",
 		File:    fileModel,
 	})
 }
 
+// @Summary Reprocess package version
+// @Description Rerun manifest extraction on an already-uploaded version (admin only)
+// @Tags packages
+// @Accept json
+// @Produce json
+// @Param name path string true "Package name"
+// @Param version path string true "Version"
+// @Security BearerAuth
+// @Success 202 {object} SuccessResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /packages/{name}/versions/{version}/reprocess [post]
+func (h *Handler) ReprocessVersion(c *gin.Context) {
+	name := c.Param("name")
+	version := c.Param("version")
+
+	pkg, err := h.db.GetPackage(c.Request.Context(), name)
+	if err != nil {
+		h.logger.Error("Failed to get package", "error", err, "name", name)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to retrieve package"})
+		return
+	}
+	if pkg == nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Package not found"})
+		return
+	}
+
+	ver, err := h.db.GetVersion(c.Request.Context(), pkg.ID, version)
+	if err != nil {
+		h.logger.Error("Failed to get version", "error", err, "package", name, "version", version)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to retrieve version"})
+		return
+	}
+	if ver == nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Version not found"})
+		return
+	}
+
+	if err := h.db.SetVersionProcessingState(c.Request.Context(), pkg.ID, version, models.ProcessingStatePending, ""); err != nil {
+		h.logger.Error("Failed to reset processing state", "error", err)
+	}
+
+	if err := h.tasks.EnqueueReprocessPackage(c.Request.Context(), worker.ReprocessPackagePayload{
+		PackageID: pkg.ID.Hex(),
+		Version:   version,
+	}); err != nil {
+		h.logger.Error("Failed to enqueue reprocessing", "error", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to enqueue reprocessing"})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, SuccessResponse{Message: "Reprocessing started"})
+}
+
 // @Summary Delete package file
 // @Description Delete a file from a package version
 // @Tags packages
@@ -236,11 +364,169 @@ func (h *Handler) DeletePackage(c *gin.Context) {
 		return
 	}
 
-	if err := h.db.RemoveFileFromVersion(c.Request.Context(), pkg.ID, version, filename); err != nil {
+	ver, err := h.db.GetVersion(c.Request.Context(), pkg.ID, version)
+	if err != nil {
+		h.logger.Error("Failed to get version", "error", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to retrieve version"})
+		return
+	}
+	if ver == nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Version not found"})
+		return
+	}
+
+	var fileKey string
+	var chunks []string
+	for _, f := range ver.Files {
+		if f.Name == filename {
+			fileKey = path.Join("packages", name, version, f.Name)
+			chunks = f.Chunks
+			break
+		}
+	}
+
+	if err := h.db.RemoveFileFromVersion(c.Request.Context(), pkg.ID, version, filename, actor(c)); err != nil {
 		h.logger.Error("Failed to remove file", "error", err)
 		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to remove file"})
 		return
 	}
 
+	if len(chunks) > 0 {
+		// Chunks may be shared with other files/versions; only the ones whose
+		// refcount drains to zero are actually orphaned.
+		drained, err := h.db.ReleaseChunks(c.Request.Context(), chunks)
+		if err != nil {
+			h.logger.Error("Failed to release chunks", "error", err)
+		}
+		for _, hash := range drained {
+			if err := h.tasks.EnqueueDeleteBlob(c.Request.Context(), worker.DeleteBlobPayload{FileKey: chunkstore.ChunkKey(hash)}); err != nil {
+				h.logger.Error("Failed to enqueue chunk deletion", "error", err)
+			}
+		}
+	} else if fileKey != "" {
+		// GC the underlying blob asynchronously; it's no longer referenced by
+		// any version once the $pull above commits.
+		if err := h.tasks.EnqueueDeleteBlob(c.Request.Context(), worker.DeleteBlobPayload{FileKey: fileKey}); err != nil {
+			h.logger.Error("Failed to enqueue blob deletion", "error", err)
+		}
+	}
+
 	c.JSON(http.StatusOK, SuccessResponse{Message: "File deleted successfully"})
 }
+
+// @Summary List dist-tags
+// @Description Get every dist-tag (e.g. "latest", "beta") set on a package
+// @Tags packages
+// @Produce json
+// @Param name path string true "Package name"
+// @Security BearerAuth
+// @Success 200 {object} map[string]string
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /packages/{name}/dist-tags [get]
+func (h *Handler) ListDistTags(c *gin.Context) {
+	name := c.Param("name")
+
+	pkg, err := h.db.GetPackage(c.Request.Context(), name)
+	if err != nil {
+		h.logger.Error("Failed to get package", "error", err, "name", name)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to retrieve package"})
+		return
+	}
+	if pkg == nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Package not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, pkg.Tags)
+}
+
+// @Summary Set a dist-tag
+// @Description Point a dist-tag at a specific version, creating or moving it
+// @Tags packages
+// @Accept json
+// @Produce json
+// @Param name path string true "Package name"
+// @Param tag path string true "Tag name, e.g. latest or beta"
+// @Param body body SetDistTagRequest true "Version the tag should point at"
+// @Security ApiKeyAuth
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /packages/{name}/dist-tags/{tag} [put]
+func (h *Handler) SetDistTag(c *gin.Context) {
+	name := c.Param("name")
+	tag := c.Param("tag")
+
+	var req SetDistTagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid request"})
+		return
+	}
+
+	pkg, err := h.db.GetPackage(c.Request.Context(), name)
+	if err != nil {
+		h.logger.Error("Failed to get package", "error", err, "name", name)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to retrieve package"})
+		return
+	}
+	if pkg == nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Package not found"})
+		return
+	}
+
+	ver, err := h.db.GetVersion(c.Request.Context(), pkg.ID, req.Version)
+	if err != nil {
+		h.logger.Error("Failed to get version", "error", err, "package", name, "version", req.Version)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to retrieve version"})
+		return
+	}
+	if ver == nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Version not found"})
+		return
+	}
+
+	if err := h.db.SetTag(c.Request.Context(), pkg.ID, tag, req.Version); err != nil {
+		h.logger.Error("Failed to set dist-tag", "error", err, "package", name, "tag", tag)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to set dist-tag"})
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{Message: "Dist-tag updated"})
+}
+
+// @Summary Remove a dist-tag
+// @Description Remove a dist-tag from a package
+// @Tags packages
+// @Produce json
+// @Param name path string true "Package name"
+// @Param tag path string true "Tag name, e.g. latest or beta"
+// @Security ApiKeyAuth
+// @Success 200 {object} SuccessResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /packages/{name}/dist-tags/{tag} [delete]
+func (h *Handler) RemoveDistTag(c *gin.Context) {
+	name := c.Param("name")
+	tag := c.Param("tag")
+
+	pkg, err := h.db.GetPackage(c.Request.Context(), name)
+	if err != nil {
+		h.logger.Error("Failed to get package", "error", err, "name", name)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to retrieve package"})
+		return
+	}
+	if pkg == nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Package not found"})
+		return
+	}
+
+	if err := h.db.RemoveTag(c.Request.Context(), pkg.ID, tag); err != nil {
+		h.logger.Error("Failed to remove dist-tag", "error", err, "package", name, "tag", tag)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to remove dist-tag"})
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{Message: "Dist-tag removed"})
+}
@@ -0,0 +1,192 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/Parallels/pd-ai-agents-registry/internal/db/mongodb"
+	"github.com/Parallels/pd-ai-agents-registry/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+const pypiJSONMediaType = "application/vnd.pypi.simple.v1+json"
+
+var pypiNormalizeRe = regexp.MustCompile(`[-_.]+`)
+
+// normalizePyPIName implements the PEP 503 package name normalization: lower
+// case, with runs of -, _, and . collapsed to a single -.
+func normalizePyPIName(name string) string {
+	return pypiNormalizeRe.ReplaceAllString(strings.ToLower(name), "-")
+}
+
+type simpleIndexFile struct {
+	Filename       string            `json:"filename"`
+	URL            string            `json:"url"`
+	Hashes         map[string]string `json:"hashes"`
+	RequiresPython string            `json:"requires-python,omitempty"`
+}
+
+type simpleIndexResponse struct {
+	Meta  simpleIndexMeta   `json:"meta"`
+	Name  string            `json:"name"`
+	Files []simpleIndexFile `json:"files"`
+}
+
+type simpleIndexMeta struct {
+	APIVersion string `json:"api-version"`
+}
+
+// SimpleIndex serves the PEP 503 "/simple/" root listing every package name,
+// which lets clients `pip install --index-url` straight against the registry.
+// @Summary PyPI simple index
+// @Description PEP 503 root index of all package names
+// @Tags pypi
+// @Produce html
+// @Router /simple [get]
+func (h *Handler) SimpleIndex(c *gin.Context) {
+	packages, err := h.listAllPackages(c.Request.Context())
+	if err != nil {
+		h.logger.Error("Failed to list packages", "error", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to retrieve packages"})
+		return
+	}
+
+	if wantsPyPIJSON(c) {
+		names := make([]map[string]string, 0, len(packages))
+		for _, pkg := range packages {
+			names = append(names, map[string]string{"name": normalizePyPIName(pkg.Name)})
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"meta":     simpleIndexMeta{APIVersion: "1.0"},
+			"projects": names,
+		})
+		return
+	}
+
+	var b strings.Builder
+	b.WriteString("<!DOCTYPE html>\n<html>\n<head><title>Simple index</title></head>\n<body>\n")
+	for _, pkg := range packages {
+		normalized := normalizePyPIName(pkg.Name)
+		fmt.Fprintf(&b, "<a href=\"/simple/%s/\">%s</a>\n", html.EscapeString(normalized), html.EscapeString(normalized))
+	}
+	b.WriteString("</body>\n</html>\n")
+
+	c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(b.String()))
+}
+
+// SimplePackage serves the PEP 503 "/simple/{name}/" page listing every file
+// across every version of a package.
+// @Summary PyPI simple package page
+// @Description PEP 503 file listing for a single package
+// @Tags pypi
+// @Produce html
+// @Param name path string true "Normalized package name"
+// @Success 200
+// @Failure 404 {object} ErrorResponse
+// @Router /simple/{name} [get]
+func (h *Handler) SimplePackage(c *gin.Context) {
+	normalized := normalizePyPIName(c.Param("name"))
+
+	packages, err := h.listAllPackages(c.Request.Context())
+	if err != nil {
+		h.logger.Error("Failed to list packages", "error", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to retrieve packages"})
+		return
+	}
+
+	var pkg *models.Package
+	for i := range packages {
+		if normalizePyPIName(packages[i].Name) == normalized {
+			pkg = &packages[i]
+			break
+		}
+	}
+	if pkg == nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Package not found"})
+		return
+	}
+
+	versions, err := h.db.ListVersions(c.Request.Context(), pkg.ID, true, false)
+	if err != nil {
+		h.logger.Error("Failed to list versions", "error", err, "package", pkg.Name)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to retrieve versions"})
+		return
+	}
+
+	if wantsPyPIJSON(c) {
+		files := make([]simpleIndexFile, 0)
+		for _, ver := range versions {
+			for _, f := range ver.Files {
+				files = append(files, simpleIndexFile{
+					Filename:       f.Name,
+					URL:            fmt.Sprintf("%s/api/v1/download/%s/%s/%s", h.config().GetBaseURL(), pkg.Name, ver.Version, f.Name),
+					Hashes:         fileHashes(f),
+					RequiresPython: strings.Join(ver.Requirements, ","),
+				})
+			}
+		}
+		c.JSON(http.StatusOK, simpleIndexResponse{
+			Meta:  simpleIndexMeta{APIVersion: "1.0"},
+			Name:  normalized,
+			Files: files,
+		})
+		return
+	}
+
+	var b strings.Builder
+	b.WriteString("<!DOCTYPE html>\n<html>\n<head><title>Links for " + html.EscapeString(normalized) + "</title></head>\n<body>\n")
+	fmt.Fprintf(&b, "<h1>Links for %s</h1>\n", html.EscapeString(normalized))
+	for _, ver := range versions {
+		for _, f := range ver.Files {
+			url := fmt.Sprintf("%s/api/v1/download/%s/%s/%s#sha256=%s", h.config().GetBaseURL(), pkg.Name, ver.Version, f.Name, f.Hash)
+			requiresPython := ""
+			if len(ver.Requirements) > 0 {
+				requiresPython = fmt.Sprintf(" data-requires-python=%q", strings.Join(ver.Requirements, ","))
+			}
+			fmt.Fprintf(&b, "<a href=\"%s\"%s>%s</a>\n", html.EscapeString(url), requiresPython, html.EscapeString(f.Name))
+		}
+	}
+	b.WriteString("</body>\n</html>\n")
+
+	c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(b.String()))
+}
+
+// fileHashes returns the full set of hashes computed for a file, for PEP 691
+// responses. Files uploaded before multi-hash support was added only have
+// the legacy Hash field, so that's used as a sha256 fallback.
+func fileHashes(f models.File) map[string]string {
+	if len(f.Hashes) > 0 {
+		return f.Hashes
+	}
+	return map[string]string{"sha256": f.Hash}
+}
+
+func wantsPyPIJSON(c *gin.Context) bool {
+	return strings.Contains(c.GetHeader("Accept"), pypiJSONMediaType)
+}
+
+// listAllPackages pages through ListPackages to build the full package
+// catalog a PyPI client expects from the simple index, since pip has no
+// notion of paginating a repository listing.
+func (h *Handler) listAllPackages(ctx context.Context) ([]models.Package, error) {
+	var all []models.Package
+	filter := mongodb.ListPackagesFilter{Sort: "name", Order: "asc", PerPage: 100}
+
+	for page := 1; ; page++ {
+		filter.Page = page
+		batch, total, err := h.db.ListPackages(ctx, filter)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, batch...)
+		if int64(len(all)) >= total || len(batch) == 0 {
+			break
+		}
+	}
+
+	return all, nil
+}
@@ -0,0 +1,253 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Parallels/pd-ai-agents-registry/internal/models"
+	"github.com/gin-gonic/gin"
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// maxReleaseNotesBytes caps how much Markdown UploadUpdate will read from a
+// notes.md part or fetch from a notes_url, so a malformed or malicious
+// source can't exhaust memory on upload.
+const maxReleaseNotesBytes = 1 << 20 // 1 MiB, plenty for a changelog
+
+// resolveReleaseNotes extracts the raw Markdown for an upload. A notes.md
+// multipart part takes precedence; failing that, a notes_url is fetched
+// server-side (similar to 1Panel's loadReleaseNotes); failing that, a plain
+// notes form field is used as-is for backward compatibility.
+func (h *Handler) resolveReleaseNotes(c *gin.Context) (string, error) {
+	if file, _, err := c.Request.FormFile("notes.md"); err == nil {
+		defer file.Close()
+		data, err := io.ReadAll(io.LimitReader(file, maxReleaseNotesBytes))
+		if err != nil {
+			return "", fmt.Errorf("reading notes.md: %w", err)
+		}
+		return string(data), nil
+	}
+
+	if notesURL := c.PostForm("notes_url"); notesURL != "" {
+		return fetchReleaseNotesURL(notesURL)
+	}
+
+	return c.PostForm("notes"), nil
+}
+
+// releaseNotesFetchClient is scoped to fetchReleaseNotesURL: it pins every
+// dial to an IP address that passed isPublicAddr, so a DNS answer that
+// changes between the lookup and the connect (rebinding) can't slip past
+// the check below.
+var releaseNotesFetchClient = http.Client{
+	Timeout: 10 * time.Second,
+	Transport: &http.Transport{
+		DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			host, port, err := net.SplitHostPort(addr)
+			if err != nil {
+				return nil, err
+			}
+			ips, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+			if err != nil {
+				return nil, err
+			}
+			for _, ip := range ips {
+				if isPublicAddr(ip.IP) {
+					return (&net.Dialer{}).DialContext(ctx, network, net.JoinHostPort(ip.IP.String(), port))
+				}
+			}
+			return nil, fmt.Errorf("notes_url resolves only to disallowed addresses for %s", host)
+		},
+	},
+}
+
+// isPublicAddr reports whether ip is safe for the server to connect to on
+// the caller's behalf: not a loopback, link-local, private, or otherwise
+// reserved range, which would let notes_url be used to reach internal
+// services or the cloud metadata endpoint (169.254.169.254).
+func isPublicAddr(ip net.IP) bool {
+	return !ip.IsLoopback() &&
+		!ip.IsPrivate() &&
+		!ip.IsLinkLocalUnicast() &&
+		!ip.IsLinkLocalMulticast() &&
+		!ip.IsUnspecified() &&
+		!ip.IsMulticast()
+}
+
+// fetchReleaseNotesURL fetches raw Markdown from a server-specified URL at
+// upload time, so a release process can point the registry at a changelog
+// file instead of uploading its contents directly. To guard against SSRF,
+// only https URLs are allowed and every resolved address is checked against
+// private/loopback/link-local ranges before the server connects to it.
+func fetchReleaseNotesURL(notesURL string) (string, error) {
+	parsed, err := url.Parse(notesURL)
+	if err != nil {
+		return "", fmt.Errorf("parsing notes_url: %w", err)
+	}
+	if parsed.Scheme != "https" {
+		return "", fmt.Errorf("notes_url must use https")
+	}
+
+	resp, err := releaseNotesFetchClient.Get(notesURL)
+	if err != nil {
+		return "", fmt.Errorf("fetching notes_url: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("fetching notes_url: unexpected status %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxReleaseNotesBytes))
+	if err != nil {
+		return "", fmt.Errorf("reading notes_url body: %w", err)
+	}
+	return string(data), nil
+}
+
+// GetReleaseNotes returns the release notes for a single version, as raw
+// Markdown (the default, and what `Accept: text/markdown` gets) or sanitized
+// HTML (`Accept: text/html`).
+// @Summary Get release notes
+// @Description Get the release notes for a version as Markdown or rendered HTML
+// @Tags updates
+// @Param version path string true "Version number"
+// @Param channel query string false "Release channel (stable, beta, nightly); defaults to stable"
+// @Success 200 {string} string
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/updates/{version}/notes [get]
+func (h *Handler) GetReleaseNotes(c *gin.Context) {
+	version := strings.TrimPrefix(c.Param("version"), "v")
+
+	channel := c.Query("channel")
+	if channel == "" {
+		channel = channelStable
+	}
+	if !isValidChannel(channel) {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid channel. Must be one of: stable, beta, nightly"})
+		return
+	}
+
+	notes, err := h.db.GetReleaseNotes(c.Request.Context(), channel, version)
+	if err != nil {
+		h.logger.Error("Failed to get release notes", "error", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to get release notes"})
+		return
+	}
+	if notes == nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "No release notes found for this version"})
+		return
+	}
+
+	h.writeReleaseNotes(c, notes)
+}
+
+// GetLatestReleaseNotes returns the release notes for the latest update on a
+// channel/platform/arch, negotiated the same way as GetReleaseNotes.
+// @Summary Get latest release notes
+// @Description Get the release notes for the latest update on a platform/arch as Markdown or rendered HTML
+// @Tags updates
+// @Param platform path string true "Platform (windows, darwin, linux)"
+// @Param arch path string true "Architecture (x86_64, i686, armv7, aarch64)"
+// @Param channel query string false "Release channel (stable, beta, nightly); defaults to stable"
+// @Success 200 {string} string
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/updates/latest/{platform}/{arch}/notes [get]
+func (h *Handler) GetLatestReleaseNotes(c *gin.Context) {
+	platform := c.Param("platform")
+	arch := c.Param("arch")
+
+	if !isValidPlatform(platform) {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid platform. Must be one of: windows, darwin, linux"})
+		return
+	}
+	if !isValidArch(arch) {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid arch. Must be one of: x86_64, i686, armv7, aarch64"})
+		return
+	}
+
+	channel := c.Query("channel")
+	if channel == "" {
+		channel = channelStable
+	}
+	if !isValidChannel(channel) {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid channel. Must be one of: stable, beta, nightly"})
+		return
+	}
+
+	// Same "rank by parsed version, not the raw string" sort GetLatestUpdate
+	// uses, so the notes returned here always match what GetLatestUpdate
+	// reports as current.
+	collection := h.db.Database().Collection("updates")
+	opts := options.FindOne().SetSort(bson.D{
+		{Key: "version_major", Value: -1},
+		{Key: "version_minor", Value: -1},
+		{Key: "version_patch", Value: -1},
+		{Key: "version_is_release", Value: -1},
+		{Key: "version_prerelease", Value: -1},
+	})
+
+	var update models.Update
+	err := collection.FindOne(c.Request.Context(), bson.M{"platform": platform, "arch": arch, "channel": channel}, opts).Decode(&update)
+	if err != nil {
+		h.logger.Error("Failed to find latest update for release notes", "error", err)
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "No updates found for this platform"})
+		return
+	}
+
+	notes, err := h.db.GetReleaseNotes(c.Request.Context(), channel, update.Version)
+	if err != nil {
+		h.logger.Error("Failed to get release notes", "error", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to get release notes"})
+		return
+	}
+	if notes == nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "No release notes found for this version"})
+		return
+	}
+
+	h.writeReleaseNotes(c, notes)
+}
+
+// writeReleaseNotes serves notes as sanitized HTML when the client asks for
+// text/html, and as raw Markdown otherwise.
+func (h *Handler) writeReleaseNotes(c *gin.Context, notes *models.ReleaseNotes) {
+	if strings.Contains(c.GetHeader("Accept"), "text/html") {
+		html, err := renderMarkdown(notes.Markdown)
+		if err != nil {
+			h.logger.Error("Failed to render release notes", "error", err)
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to render release notes"})
+			return
+		}
+		c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(html))
+		return
+	}
+
+	c.Data(http.StatusOK, "text/markdown; charset=utf-8", []byte(notes.Markdown))
+}
+
+// renderMarkdown converts raw Markdown to HTML and runs it through
+// bluemonday's UGC policy before returning it, since release notes are
+// submitted by whoever runs UploadUpdate and get served straight to a
+// browser that asks for text/html.
+func renderMarkdown(raw string) (string, error) {
+	var buf bytes.Buffer
+	if err := goldmark.Convert([]byte(raw), &buf); err != nil {
+		return "", err
+	}
+	return bluemonday.UGCPolicy().Sanitize(buf.String()), nil
+}
@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/Parallels/pd-ai-agents-registry/internal/registry"
+	"github.com/gin-gonic/gin"
+)
+
+// GetRepositoryIndex serves a single file out of a format-native repository
+// index (APKINDEX.tar.gz, Packages/Release, repodata/*.xml.gz, or a Cargo
+// sparse-index entry), building and caching the index on first request for
+// the current catalog state. See internal/registry for how formats are
+// generated.
+// @Summary Serve a format-native repository index file
+// @Description Serve one file of the apk/deb/rpm/cargo repository index, generating and caching it on demand
+// @Tags registry
+// @Produce octet-stream
+// @Param format path string true "Repository format (apk, deb, rpm, cargo)"
+// @Param path path string true "File path within the format's index"
+// @Success 200 {file} binary
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /repo/{format}/{path} [get]
+func (h *Handler) GetRepositoryIndex(c *gin.Context) {
+	format := c.Param("format")
+	if !registry.SupportedFormat(format) {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Unknown repository format"})
+		return
+	}
+
+	requestedPath := strings.TrimPrefix(c.Param("path"), "/")
+	if requestedPath == "" {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "File not found"})
+		return
+	}
+
+	files, err := registry.Generate(c.Request.Context(), h.db.Client, format, h.config().GetBaseURL(), registry.Options{
+		APKSigningKey: h.apkSigningKey,
+		APKKeyName:    h.config().Registry.APKKeyName,
+	})
+	if err != nil {
+		h.logger.Error("Failed to generate repository index", "error", err, "format", format)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to generate repository index"})
+		return
+	}
+
+	content, ok := files[requestedPath]
+	if !ok {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "File not found"})
+		return
+	}
+
+	c.Data(http.StatusOK, repositoryFileContentType(requestedPath), content)
+}
+
+// repositoryFileContentType maps a repository index file's extension to
+// the content type its package manager expects, falling back to Go's
+// standard extension-based detection (and finally octet-stream) for
+// anything not special-cased.
+func repositoryFileContentType(name string) string {
+	switch path.Ext(name) {
+	case ".gz":
+		return "application/gzip"
+	case ".xml":
+		return "application/xml"
+	case ".json":
+		return "application/json"
+	}
+	if ct := mime.TypeByExtension(path.Ext(name)); ct != "" {
+		return ct
+	}
+	return "application/octet-stream"
+}
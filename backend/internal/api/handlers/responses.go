@@ -14,3 +14,15 @@ type UploadResponse struct {
 	Message string       `json:"message"`
 	File    *models.File `json:"file"`
 }
+
+type ListPackagesResponse struct {
+	Items   []models.Package `json:"items"`
+	Total   int64            `json:"total"`
+	Page    int              `json:"page"`
+	PerPage int              `json:"per_page"`
+}
+
+// SetDistTagRequest is the body of PUT /packages/{name}/dist-tags/{tag}.
+type SetDistTagRequest struct {
+	Version string `json:"version" binding:"required"`
+}
@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"net/http"
+	"time"
+
+	"github.com/Parallels/pd-ai-agents-registry/internal/models"
+	"github.com/Parallels/pd-ai-agents-registry/internal/signing"
+	"github.com/gin-gonic/gin"
+)
+
+type SignFileRequest struct {
+	Algorithm string `json:"algorithm" binding:"required"`
+	KeyID     string `json:"key_id" binding:"required"`
+	Signature string `json:"signature" binding:"required"`
+}
+
+type TrustedKeysResponse struct {
+	Keys []signing.TrustedKey `json:"keys"`
+}
+
+// @Summary Sign a package file
+// @Description Attach a detached ed25519 signature, verified against a trusted publisher key, to an uploaded file
+// @Tags packages
+// @Accept json
+// @Produce json
+// @Param name path string true "Package name"
+// @Param version path string true "Version"
+// @Param filename path string true "Filename"
+// @Param body body SignFileRequest true "Detached signature"
+// @Security ApiKeyAuth
+// @Success 200 {object} models.FileSignature
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 422 {object} ErrorResponse
+// @Router /packages/{name}/versions/{version}/{filename}/sign [post]
+func (h *Handler) SignPackageFile(c *gin.Context) {
+	name := c.Param("name")
+	version := c.Param("version")
+	filename := c.Param("filename")
+
+	var req SignFileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid request"})
+		return
+	}
+	if req.Algorithm != signing.AlgorithmEd25519 {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Unsupported signature algorithm"})
+		return
+	}
+
+	pkg, err := h.db.GetPackage(c.Request.Context(), name)
+	if err != nil {
+		h.logger.Error("Failed to get package", "error", err, "name", name)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to retrieve package"})
+		return
+	}
+	if pkg == nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Package not found"})
+		return
+	}
+
+	ver, err := h.db.GetVersion(c.Request.Context(), pkg.ID, version)
+	if err != nil {
+		h.logger.Error("Failed to get version", "error", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to retrieve version"})
+		return
+	}
+	if ver == nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Version not found"})
+		return
+	}
+
+	var fileInfo *models.File
+	for i := range ver.Files {
+		if ver.Files[i].Name == filename {
+			fileInfo = &ver.Files[i]
+			break
+		}
+	}
+	if fileInfo == nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "File not found"})
+		return
+	}
+
+	sha256Hash := fileInfo.Hashes["sha256"]
+	if sha256Hash == "" {
+		sha256Hash = fileInfo.Hash
+	}
+
+	sigBytes, err := base64.StdEncoding.DecodeString(req.Signature)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Signature must be base64-encoded"})
+		return
+	}
+
+	message := signing.CanonicalMessage(pkg.Name, version, filename, sha256Hash)
+	if !h.signing.Verify(req.KeyID, message, sigBytes) {
+		c.JSON(http.StatusUnprocessableEntity, ErrorResponse{Error: "Signature verification failed"})
+		return
+	}
+
+	signature := models.FileSignature{
+		Algorithm: req.Algorithm,
+		KeyID:     req.KeyID,
+		Value:     req.Signature,
+		SignedAt:  time.Now(),
+	}
+	if err := h.db.SetFileSignature(c.Request.Context(), pkg.ID, version, filename, signature); err != nil {
+		h.logger.Error("Failed to store signature", "error", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to store signature"})
+		return
+	}
+
+	c.JSON(http.StatusOK, signature)
+}
+
+// @Summary List trusted publisher keys
+// @Description Public discovery endpoint for the ed25519 keys the registry trusts when verifying package signatures
+// @Tags packages
+// @Produce json
+// @Success 200 {object} TrustedKeysResponse
+// @Router /.well-known/pd-agents-registry/trusted-keys.json [get]
+func (h *Handler) TrustedKeys(c *gin.Context) {
+	c.JSON(http.StatusOK, TrustedKeysResponse{Keys: h.signing.TrustedKeys()})
+}
@@ -2,6 +2,9 @@ package handlers
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"fmt"
 	"io"
 	"net/http"
@@ -11,6 +14,7 @@ import (
 	"time"
 
 	"github.com/Parallels/pd-ai-agents-registry/internal/models"
+	"github.com/Parallels/pd-ai-agents-registry/internal/semver"
 	"github.com/gin-gonic/gin"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
@@ -27,6 +31,9 @@ import (
 // @Param platform path string true "Platform (windows, darwin, linux)"
 // @Param arch path string true "Architecture (x86_64, i686, armv7, aarch64)"
 // @Param file formData file true "Update file"
+// @Param channel formData string false "Release channel (stable, beta, nightly); defaults to stable"
+// @Param notes.md formData file false "Release notes, as a Markdown file"
+// @Param notes_url formData string false "URL to fetch the release notes Markdown from"
 // @Success 201 {object} map[string]string
 // @Failure 400 {object} ErrorResponse
 // @Failure 409 {object} ErrorResponse
@@ -58,6 +65,23 @@ func (h *Handler) UploadUpdate(c *gin.Context) {
 
 	version = strings.TrimPrefix(version, "v")
 
+	// Parse as SemVer 2.0.0 up front so an invalid version is rejected at
+	// upload time rather than silently sorting wrong later.
+	parsedVersion, err := semver.Parse(version)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: fmt.Sprintf("Invalid version: %v", err)})
+		return
+	}
+
+	channel := c.PostForm("channel")
+	if channel == "" {
+		channel = channelStable
+	}
+	if !isValidChannel(channel) {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid channel. Must be one of: stable, beta, nightly"})
+		return
+	}
+
 	// Check if version already exists for this platform and architecture
 	collection := h.db.Database().Collection("updates")
 	count, err := collection.CountDocuments(
@@ -108,13 +132,16 @@ func (h *Handler) UploadUpdate(c *gin.Context) {
 	// Reset reader position for later upload
 	_, _ = signature.Seek(0, 0)
 
-	// upload update file to s3
+	// upload update file to s3, hashing it as it streams through so no
+	// second pass over the file is needed
+	hasher := sha256.New()
 	updateKey := fmt.Sprintf("updates/%s/%s/%s/%s", version, platform, arch, header.Filename)
-	if err := h.storage.Upload(c.Request.Context(), updateKey, file); err != nil {
-		h.logger.Error("Failed to upload update file to S3", err)
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to upload update file to S3"})
+	if _, _, err := h.storage.Put(c.Request.Context(), updateKey, io.TeeReader(file, hasher), header.Header.Get("Content-Type")); err != nil {
+		h.logger.Error("Failed to upload update file to storage", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to upload update file to storage"})
 		return
 	}
+	sha256Hash := hex.EncodeToString(hasher.Sum(nil))
 
 	// Create download URL
 	downloadURL := fmt.Sprintf("/api/v1/updates/download/%s/%s/%s/%s", version, platform, arch, header.Filename)
@@ -122,22 +149,36 @@ func (h *Handler) UploadUpdate(c *gin.Context) {
 	// Create update record in database
 	now := time.Now()
 	update := models.Update{
-		Version:     version,
-		Platform:    platform,
-		Arch:        arch,
-		Filename:    header.Filename,
-		FileSize:    header.Size,
-		Signature:   string(signatureContent),
-		ReleaseDate: now,
-		DownloadURL: downloadURL,
-		CreatedAt:   now,
-		UpdatedAt:   now,
-	}
-
-	// Get notes from form if provided
-	if notes := c.PostForm("notes"); notes != "" {
-		update.Notes = notes
+		Version:           version,
+		VersionMajor:      parsedVersion.Major,
+		VersionMinor:      parsedVersion.Minor,
+		VersionPatch:      parsedVersion.Patch,
+		VersionPrerelease: parsedVersion.Prerelease,
+		VersionIsRelease:  parsedVersion.Prerelease == "",
+		Channel:           channel,
+		Platform:          platform,
+		Arch:              arch,
+		Filename:          header.Filename,
+		FileSize:          header.Size,
+		Sha256:            sha256Hash,
+		Signature:         string(signatureContent),
+		ReleaseDate:       now,
+		DownloadURL:       downloadURL,
+		CreatedAt:         now,
+		UpdatedAt:         now,
+	}
+
+	// Resolve release notes from a notes.md part, a notes_url, or a legacy
+	// plain notes field, and keep the raw Markdown in its own collection (see
+	// release_notes.go) instead of inline on the LatestVersion document
+	// clients poll on every launch.
+	notesMarkdown, err := h.resolveReleaseNotes(c)
+	if err != nil {
+		h.logger.Error("Failed to resolve release notes", err)
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: fmt.Sprintf("Failed to resolve release notes: %v", err)})
+		return
 	}
+	update.Notes = notesMarkdown
 
 	// Insert into database
 	_, err = collection.InsertOne(c.Request.Context(), update)
@@ -147,6 +188,13 @@ func (h *Handler) UploadUpdate(c *gin.Context) {
 		return
 	}
 
+	if notesMarkdown != "" {
+		if err := h.db.UpsertReleaseNotes(c.Request.Context(), channel, version, notesMarkdown); err != nil {
+			h.logger.Error("Failed to save release notes", "error", err)
+			// Don't fail the upload over this, the update itself succeeded
+		}
+	}
+
 	// Update the LatestVersion document
 	if err := h.updateLatestVersionDocument(c.Request.Context(), update); err != nil {
 		h.logger.Error("Failed to update latest version document", err)
@@ -169,6 +217,7 @@ func (h *Handler) UploadUpdate(c *gin.Context) {
 // @Tags updates
 // @Produce json
 // @Param platform path string true "Platform (windows, macos, linux)"
+// @Param channel query string false "Release channel (stable, beta, nightly); defaults to stable"
 // @Success 200 {object} models.UpdateMetadata
 // @Failure 400 {object} ErrorResponse
 // @Failure 404 {object} ErrorResponse
@@ -190,16 +239,36 @@ func (h *Handler) GetLatestUpdate(c *gin.Context) {
 		return
 	}
 
+	channel := c.Query("channel")
+	if channel == "" {
+		channel = channelStable
+	}
+	if !isValidChannel(channel) {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid channel. Must be one of: stable, beta, nightly"})
+		return
+	}
+
 	// Find the latest update for the platform
 	collection := h.db.Database().Collection("updates")
 
-	// Sort by version in descending order (assuming semantic versioning)
-	opts := options.FindOne().SetSort(bson.D{{Key: "version", Value: -1}})
+	// Sort by the parsed numeric components rather than the raw version
+	// string, so e.g. "1.10.0" correctly outranks "1.9.0". Release ranks
+	// above prerelease at the same major.minor.patch (version_is_release
+	// descending), then prerelease identifiers break remaining ties; this
+	// is a best-effort lexicographic approximation of full SemVer
+	// prerelease precedence, which Mongo's sort can't fully express.
+	opts := options.FindOne().SetSort(bson.D{
+		{Key: "version_major", Value: -1},
+		{Key: "version_minor", Value: -1},
+		{Key: "version_patch", Value: -1},
+		{Key: "version_is_release", Value: -1},
+		{Key: "version_prerelease", Value: -1},
+	})
 
 	var update models.Update
 	err := collection.FindOne(
 		c.Request.Context(),
-		bson.M{"platform": platform, "arch": arch},
+		bson.M{"platform": platform, "arch": arch, "channel": channel},
 		opts,
 	).Decode(&update)
 	if err != nil {
@@ -213,6 +282,8 @@ func (h *Handler) GetLatestUpdate(c *gin.Context) {
 		Version:     update.Version,
 		Platform:    update.Platform,
 		Arch:        update.Arch,
+		Channel:     update.Channel,
+		Sha256:      update.Sha256,
 		ReleaseDate: update.ReleaseDate,
 		Notes:       update.Notes,
 		DownloadURL: update.DownloadURL,
@@ -256,8 +327,16 @@ func (h *Handler) DownloadUpdate(c *gin.Context) {
 	// Trim v prefix if present
 	version = strings.TrimPrefix(version, "v")
 
-	// Generate S3 key
-	key := fmt.Sprintf("updates/%s/%s/%s/%s", version, platform, arch, filename)
+	update, key, err := h.lookupUpdateForDownload(c.Request.Context(), version, platform, arch, filename)
+	if err != nil {
+		h.logger.Error("Failed to look up update record", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to look up update record"})
+		return
+	}
+	if update == nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Update file not found"})
+		return
+	}
 
 	// Check if file exists
 	exists, err := h.storage.Exists(c.Request.Context(), key)
@@ -272,18 +351,28 @@ func (h *Handler) DownloadUpdate(c *gin.Context) {
 		return
 	}
 
-	// Get file size
+	// Get file size and make sure it still matches what was recorded at
+	// upload time, to catch bucket corruption or a partial upload before
+	// streaming a broken file to a client.
 	size, err := h.storage.Size(c.Request.Context(), key)
 	if err != nil {
 		h.logger.Error("Failed to get file size", err)
 		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to get file size"})
 		return
 	}
+	if update.FileSize != 0 && size != update.FileSize {
+		h.logger.Error("Stored update size mismatch, bucket corruption suspected", fmt.Errorf("want %d bytes, got %d", update.FileSize, size))
+		c.JSON(http.StatusBadGateway, ErrorResponse{Error: "Update file failed storage integrity check"})
+		return
+	}
 
 	c.Header("Content-Length", strconv.Itoa(int(size)))
+	if digest := sha256Digest(update.Sha256); digest != "" {
+		c.Header("Digest", digest)
+	}
 
 	// Get file from S3
-	reader, err := h.storage.Download(c.Request.Context(), key)
+	reader, err := h.storage.Get(c.Request.Context(), key)
 	if err != nil {
 		h.logger.Error("Failed to download update file", err)
 		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to download update file"})
@@ -306,24 +395,135 @@ func (h *Handler) DownloadUpdate(c *gin.Context) {
 	}
 }
 
+// HeadUpdate returns the same Digest and Content-Length headers DownloadUpdate
+// would, without transferring the file body, so a client can verify an
+// update it already has without re-downloading it.
+// @Summary Get update digest and size
+// @Description Get the checksum and size of an update file without downloading it
+// @Tags updates
+// @Param version path string true "Version number"
+// @Param platform path string true "Platform (windows, macos, linux)"
+// @Param arch path string true "Architecture (x86, x64, arm64)"
+// @Param filename path string true "Filename"
+// @Success 200
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/updates/download/{version}/{platform}/{arch}/{filename} [head]
+func (h *Handler) HeadUpdate(c *gin.Context) {
+	version := c.Param("version")
+	platform := c.Param("platform")
+	arch := c.Param("arch")
+	filename := c.Param("filename")
+
+	if !isValidPlatform(platform) {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid platform. Must be one of: windows, darwin, linux"})
+		return
+	}
+	if !isValidArch(arch) {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid arch. Must be one of: x86_64, i686, armv7, aarch64"})
+		return
+	}
+
+	version = strings.TrimPrefix(version, "v")
+
+	update, key, err := h.lookupUpdateForDownload(c.Request.Context(), version, platform, arch, filename)
+	if err != nil {
+		h.logger.Error("Failed to look up update record", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to look up update record"})
+		return
+	}
+	if update == nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Update file not found"})
+		return
+	}
+
+	size, err := h.storage.Size(c.Request.Context(), key)
+	if err != nil {
+		h.logger.Error("Failed to get file size", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to get file size"})
+		return
+	}
+	if update.FileSize != 0 && size != update.FileSize {
+		h.logger.Error("Stored update size mismatch, bucket corruption suspected", fmt.Errorf("want %d bytes, got %d", update.FileSize, size))
+		c.JSON(http.StatusBadGateway, ErrorResponse{Error: "Update file failed storage integrity check"})
+		return
+	}
+
+	c.Header("Content-Length", strconv.Itoa(int(size)))
+	if digest := sha256Digest(update.Sha256); digest != "" {
+		c.Header("Digest", digest)
+	}
+	c.Status(http.StatusOK)
+}
+
+// lookupUpdateForDownload finds the Update record for version/platform/arch
+// and returns it along with the storage key its file lives under. It
+// returns a nil update (no error) if no such update exists.
+func (h *Handler) lookupUpdateForDownload(ctx context.Context, version, platform, arch, filename string) (*models.Update, string, error) {
+	collection := h.db.Database().Collection("updates")
+
+	var update models.Update
+	err := collection.FindOne(ctx, bson.M{"version": version, "platform": platform, "arch": arch}).Decode(&update)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, "", nil
+		}
+		return nil, "", err
+	}
+
+	key := fmt.Sprintf("updates/%s/%s/%s/%s", version, platform, arch, filename)
+	return &update, key, nil
+}
+
+// sha256Digest formats an RFC 3230 Digest header value (e.g.
+// "sha-256=<base64>") from a hex-encoded sha256 sum, or "" if hexSum is
+// empty or malformed.
+func sha256Digest(hexSum string) string {
+	if hexSum == "" {
+		return ""
+	}
+	raw, err := hex.DecodeString(hexSum)
+	if err != nil {
+		return ""
+	}
+	return "sha-256=" + base64.StdEncoding.EncodeToString(raw)
+}
+
 // ListUpdates lists all available updates
 // @Summary List updates
 // @Description List all available updates
 // @Tags updates
 // @Produce json
+// @Param channel query string false "Release channel (stable, beta, nightly); lists all channels if omitted"
 // @Success 200 {array} models.UpdateMetadata
 // @Failure 500 {object} ErrorResponse
 // @Router /api/v1/updates [get]
 func (h *Handler) ListUpdates(c *gin.Context) {
 	collection := h.db.Database().Collection("updates")
 
+	// Filter by channel only if the caller asked for one; otherwise list
+	// updates across all channels.
+	filter := bson.M{}
+	if channel := c.Query("channel"); channel != "" {
+		if !isValidChannel(channel) {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid channel. Must be one of: stable, beta, nightly"})
+			return
+		}
+		filter["channel"] = channel
+	}
+
 	// Find all updates, sorted by version and platform
 	cursor, err := collection.Find(
 		c.Request.Context(),
-		bson.M{},
+		filter,
 		options.Find().SetSort(bson.D{
 			{Key: "platform", Value: 1},
-			{Key: "version", Value: -1},
+			{Key: "version_major", Value: -1},
+			{Key: "version_minor", Value: -1},
+			{Key: "version_patch", Value: -1},
+			{Key: "version_is_release", Value: -1},
+			{Key: "version_prerelease", Value: -1},
 		}),
 	)
 	if err != nil {
@@ -348,6 +548,8 @@ func (h *Handler) ListUpdates(c *gin.Context) {
 			Version:     update.Version,
 			Platform:    update.Platform,
 			Arch:        update.Arch,
+			Channel:     update.Channel,
+			Sha256:      update.Sha256,
 			ReleaseDate: update.ReleaseDate,
 			Notes:       update.Notes,
 			DownloadURL: update.DownloadURL,
@@ -453,7 +655,21 @@ func (h *Handler) DeleteUpdate(c *gin.Context) {
 	})
 }
 
+// channelStable is the default release channel for uploads and lookups that
+// don't specify one, so the production "latest" endpoint Tauri clients poll
+// never picks up a beta or nightly build.
+const channelStable = "stable"
+
 // Helper functions
+func isValidChannel(channel string) bool {
+	validChannels := map[string]bool{
+		"stable":  true,
+		"beta":    true,
+		"nightly": true,
+	}
+	return validChannels[channel]
+}
+
 func isValidPlatform(platform string) bool {
 	validPlatforms := map[string]bool{
 		"windows": true,
@@ -515,21 +731,24 @@ func getContentType(filename string) string {
 	}
 }
 
-// updateLatestVersionDocument updates the LatestVersion document with the latest update information
+// updateLatestVersionDocument updates the LatestVersion document for the
+// update's channel with the latest update information. One document is kept
+// per channel, so publishing a beta never affects what the stable channel's
+// "latest" endpoint reports.
 func (h *Handler) updateLatestVersionDocument(ctx context.Context, update models.Update) error {
 	collection := h.db.Database().Collection("latest_version")
 
-	// Get the current latest version document
+	// Get the current latest version document for this channel
 	var latestVersion models.LatestVersion
-	err := collection.FindOne(ctx, bson.M{}).Decode(&latestVersion)
+	err := collection.FindOne(ctx, bson.M{"channel": update.Channel}).Decode(&latestVersion)
 	if err != nil && err != mongo.ErrNoDocuments {
 		return fmt.Errorf("error finding latest version document: %w", err)
 	}
+	latestVersion.Channel = update.Channel
 
-	// If no document exists or the new version is newer, update the version and notes
+	// If no document exists or the new version is newer, update the version
 	if err == mongo.ErrNoDocuments || compareVersions(update.Version, latestVersion.Version) > 0 {
 		latestVersion.Version = update.Version
-		latestVersion.Notes = update.Notes
 		latestVersion.PubDate = update.ReleaseDate.Format(time.RFC3339)
 	} else if update.Version != latestVersion.Version {
 		// If this is an older version, don't update the document
@@ -539,7 +758,8 @@ func (h *Handler) updateLatestVersionDocument(ctx context.Context, update models
 	// Update the platform-specific information
 	platformKey := fmt.Sprintf("%s-%s", update.Platform, update.Arch)
 	platformInfo := models.LatestVersionPlatform{
-		URL: update.DownloadURL,
+		URL:    update.DownloadURL,
+		Sha256: update.Sha256,
 	}
 
 	// Add signature URL if available
@@ -557,10 +777,6 @@ func (h *Handler) updateLatestVersionDocument(ctx context.Context, update models
 		}
 	}
 
-	if update.Notes != "" {
-		latestVersion.Notes = update.Notes
-	}
-
 	// Update the appropriate platform field based on the platform key
 	latestVersion.Platforms[platformKey] = platformInfo
 
@@ -568,7 +784,7 @@ func (h *Handler) updateLatestVersionDocument(ctx context.Context, update models
 	opts := options.Update().SetUpsert(true)
 	_, err = collection.UpdateOne(
 		ctx,
-		bson.M{}, // Empty filter to match any document
+		bson.M{"channel": update.Channel},
 		bson.M{"$set": latestVersion},
 		opts,
 	)
@@ -579,40 +795,29 @@ func (h *Handler) updateLatestVersionDocument(ctx context.Context, update models
 	return nil
 }
 
-// compareVersions compares two semantic version strings
+// compareVersions compares two semantic version strings under full SemVer
+// 2.0.0 precedence (see internal/semver). Versions that fail to parse sort
+// as lower than any valid version, so a malformed value already in the
+// database can't wrongly win promotion to latest.
 // Returns:  1 if v1 > v2
 //
 //	-1 if v1 < v2
 //	 0 if v1 == v2
 func compareVersions(v1, v2 string) int {
-	// Remove 'v' prefix if present
 	v1 = strings.TrimPrefix(v1, "v")
 	v2 = strings.TrimPrefix(v2, "v")
 
-	// Split versions into components
-	v1Parts := strings.Split(v1, ".")
-	v2Parts := strings.Split(v2, ".")
-
-	// Compare each component
-	for i := 0; i < len(v1Parts) && i < len(v2Parts); i++ {
-		v1Num, _ := strconv.Atoi(v1Parts[i])
-		v2Num, _ := strconv.Atoi(v2Parts[i])
-
-		if v1Num > v2Num {
-			return 1
-		} else if v1Num < v2Num {
+	cmp, err := semver.Compare(v1, v2)
+	if err != nil {
+		if v1 == v2 {
+			return 0
+		}
+		if _, err1 := semver.Parse(v1); err1 != nil {
 			return -1
 		}
-	}
-
-	// If we get here, the common parts are equal, so the longer one is greater
-	if len(v1Parts) > len(v2Parts) {
 		return 1
-	} else if len(v1Parts) < len(v2Parts) {
-		return -1
 	}
-
-	return 0
+	return cmp
 }
 
 // GetLatestVersionInfo returns the latest version information for all platforms
@@ -620,6 +825,7 @@ func compareVersions(v1, v2 string) int {
 // @Description Get information about the latest version for all platforms
 // @Tags updates
 // @Produce json
+// @Param channel query string false "Release channel (stable, beta, nightly); defaults to stable"
 // @Success 200 {object} models.LatestVersion
 // @Failure 404 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
@@ -627,8 +833,17 @@ func compareVersions(v1, v2 string) int {
 func (h *Handler) GetLatestVersionInfo(c *gin.Context) {
 	collection := h.db.Database().Collection("latest_version")
 
+	channel := c.Query("channel")
+	if channel == "" {
+		channel = channelStable
+	}
+	if !isValidChannel(channel) {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid channel. Must be one of: stable, beta, nightly"})
+		return
+	}
+
 	var latestVersion models.LatestVersion
-	err := collection.FindOne(c.Request.Context(), bson.M{}).Decode(&latestVersion)
+	err := collection.FindOne(c.Request.Context(), bson.M{"channel": channel}).Decode(&latestVersion)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			c.JSON(http.StatusNotFound, ErrorResponse{Error: "No updates available"})
@@ -643,11 +858,12 @@ func (h *Handler) GetLatestVersionInfo(c *gin.Context) {
 	platforms := make(map[string]models.LatestVersionPlatform)
 	for _, platform := range generateAllPlatformKeys() {
 		if info, ok := latestVersion.Platforms[platform]; ok {
-			info.URL = fmt.Sprintf("%s%s", h.cfg.GetBaseURL(), info.URL)
+			info.URL = fmt.Sprintf("%s%s", h.config().GetBaseURL(), info.URL)
 			platforms[platform] = info
 		}
 	}
 
 	latestVersion.Platforms = platforms
+	latestVersion.NotesURL = fmt.Sprintf("%s/api/v1/updates/%s/notes?channel=%s", h.config().GetBaseURL(), latestVersion.Version, channel)
 	c.JSON(http.StatusOK, latestVersion)
 }
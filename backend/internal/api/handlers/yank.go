@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// YankVersionRequest is the body of POST .../versions/{version}/yank.
+type YankVersionRequest struct {
+	Reason string `json:"reason"`
+}
+
+// @Summary Yank a package version
+// @Description Mark a version as yanked: GetVersion and an exact download still resolve it, but ListVersions and ResolveVersion's default results leave it out, the way Cargo and npm handle a yank instead of a hard delete
+// @Tags packages
+// @Accept json
+// @Produce json
+// @Param name path string true "Package name"
+// @Param version path string true "Version"
+// @Param body body YankVersionRequest false "Reason for the yank"
+// @Security ApiKeyAuth
+// @Success 200 {object} models.Version
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /packages/{name}/versions/{version}/yank [post]
+func (h *Handler) YankVersion(c *gin.Context) {
+	name := c.Param("name")
+	version := c.Param("version")
+
+	var req YankVersionRequest
+	_ = c.ShouldBindJSON(&req)
+
+	pkg, err := h.db.GetPackage(c.Request.Context(), name)
+	if err != nil {
+		h.logger.Error("Failed to get package", "error", err, "name", name)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to retrieve package"})
+		return
+	}
+	if pkg == nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Package not found"})
+		return
+	}
+
+	if err := h.db.YankVersion(c.Request.Context(), pkg.ID, version, actor(c), req.Reason); err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: "Version not found"})
+			return
+		}
+		h.logger.Error("Failed to yank version", "error", err, "package", name, "version", version)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to yank version"})
+		return
+	}
+
+	ver, err := h.db.GetVersion(c.Request.Context(), pkg.ID, version)
+	if err != nil {
+		h.logger.Error("Failed to get version", "error", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to retrieve version"})
+		return
+	}
+	c.JSON(http.StatusOK, ver)
+}
+
+// @Summary Unyank a package version
+// @Description Reverse YankVersion, making the version resolvable again through ListVersions and ResolveVersion's default results
+// @Tags packages
+// @Produce json
+// @Param name path string true "Package name"
+// @Param version path string true "Version"
+// @Security ApiKeyAuth
+// @Success 200 {object} models.Version
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /packages/{name}/versions/{version}/unyank [post]
+func (h *Handler) UnyankVersion(c *gin.Context) {
+	name := c.Param("name")
+	version := c.Param("version")
+
+	pkg, err := h.db.GetPackage(c.Request.Context(), name)
+	if err != nil {
+		h.logger.Error("Failed to get package", "error", err, "name", name)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to retrieve package"})
+		return
+	}
+	if pkg == nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Package not found"})
+		return
+	}
+
+	if err := h.db.UnyankVersion(c.Request.Context(), pkg.ID, version, actor(c)); err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: "Version not found"})
+			return
+		}
+		h.logger.Error("Failed to unyank version", "error", err, "package", name, "version", version)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to unyank version"})
+		return
+	}
+
+	ver, err := h.db.GetVersion(c.Request.Context(), pkg.ID, version)
+	if err != nil {
+		h.logger.Error("Failed to get version", "error", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to retrieve version"})
+		return
+	}
+	c.JSON(http.StatusOK, ver)
+}
+
+// @Summary Purge a package version
+// @Description Permanently delete a version (admin only). Only allowed once the version has been yanked for at least config.VersionsConfig.PurgeGraceHours, so mirrors and lockfiles have time to notice the yank first
+// @Tags packages
+// @Produce json
+// @Param name path string true "Package name"
+// @Param version path string true "Version"
+// @Security BearerAuth
+// @Success 200 {object} SuccessResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /packages/{name}/versions/{version}/purge [delete]
+func (h *Handler) PurgeVersion(c *gin.Context) {
+	name := c.Param("name")
+	version := c.Param("version")
+
+	pkg, err := h.db.GetPackage(c.Request.Context(), name)
+	if err != nil {
+		h.logger.Error("Failed to get package", "error", err, "name", name)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to retrieve package"})
+		return
+	}
+	if pkg == nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Package not found"})
+		return
+	}
+
+	gracePeriod := time.Duration(h.config().Versions.PurgeGraceHours) * time.Hour
+	if err := h.db.PurgeVersion(c.Request.Context(), pkg.ID, version, actor(c), gracePeriod); err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: "Version not found"})
+			return
+		}
+		// PurgeVersion's only other failure mode is the "not yanked yet" /
+		// "grace period hasn't elapsed" business rule; its error text is
+		// already client-safe, so it's returned as-is instead of a generic
+		// 500.
+		c.JSON(http.StatusConflict, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{Message: "Version purged"})
+}
@@ -0,0 +1,81 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/Parallels/pd-ai-agents-registry/internal/auth"
+	"github.com/Parallels/pd-ai-agents-registry/internal/db/mongodb"
+	"github.com/gin-gonic/gin"
+)
+
+// AuthMiddleware guards routes with either a bearer JWT (JWT) or a static
+// API key (APIKey). JWT verification also checks the JTI denylist, so a
+// token can be revoked before it naturally expires.
+type AuthMiddleware struct {
+	tokens *auth.Issuer
+	db     *mongodb.CachingClient
+	apiKey string
+}
+
+// NewAuthMiddleware builds an AuthMiddleware. tokens verifies and inspects
+// access tokens, db backs the JTI denylist check, and apiKey is the static
+// key accepted by APIKey()-protected routes.
+func NewAuthMiddleware(tokens *auth.Issuer, db *mongodb.CachingClient, apiKey string) *AuthMiddleware {
+	return &AuthMiddleware{tokens: tokens, db: db, apiKey: apiKey}
+}
+
+// JWT requires a valid, non-revoked bearer access token. On success it
+// sets "user_id", "jti", and "jti_expires_at" in the request context for
+// downstream handlers (e.g. Logout, LogoutAll).
+func (m *AuthMiddleware) JWT() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		header := c.GetHeader("Authorization")
+		tokenString, ok := strings.CutPrefix(header, "Bearer ")
+		if !ok || tokenString == "" {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Missing or malformed Authorization header"})
+			c.Abort()
+			return
+		}
+
+		claims, err := m.tokens.ParseAccessToken(tokenString)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
+			c.Abort()
+			return
+		}
+
+		denylisted, err := m.db.IsJTIDenylisted(c.Request.Context(), claims.ID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+			c.Abort()
+			return
+		}
+		if denylisted {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Token has been revoked"})
+			c.Abort()
+			return
+		}
+
+		c.Set("user_id", claims.Subject)
+		c.Set("jti", claims.ID)
+		if claims.ExpiresAt != nil {
+			c.Set("jti_expires_at", claims.ExpiresAt.Time)
+		}
+		c.Next()
+	}
+}
+
+// APIKey requires the X-API-Key header to match the configured admin key.
+func (m *AuthMiddleware) APIKey() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader("X-API-Key")
+		if key == "" || subtle.ConstantTimeCompare([]byte(key), []byte(m.apiKey)) != 1 {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or missing API key"})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
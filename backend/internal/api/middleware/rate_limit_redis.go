@@ -0,0 +1,129 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Parallels/pd-ai-agents-registry/internal/config"
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+)
+
+// tokenBucketScript atomically refills and drains a token bucket stored as a
+// Redis hash, so every replica behind a load balancer shares one bucket per
+// client IP instead of each node keeping its own.
+//
+// KEYS[1] = bucket key ("rl:{ip}")
+// ARGV[1] = requests per second
+// ARGV[2] = burst size
+// ARGV[3] = now, in nanoseconds
+// ARGV[4] = key TTL, in seconds
+//
+// Returns {allowed (0/1), retry_after_ms}.
+const tokenBucketScript = `
+local tokens_key = "tokens"
+local updated_key = "updated_at_ns"
+
+local rps = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+local ttl = tonumber(ARGV[4])
+
+local bucket = redis.call("HMGET", KEYS[1], tokens_key, updated_key)
+local tokens = tonumber(bucket[1])
+local updated_at = tonumber(bucket[2])
+
+if tokens == nil then
+	tokens = burst
+	updated_at = now
+end
+
+local elapsed_seconds = math.max(0, now - updated_at) / 1e9
+tokens = math.min(burst, tokens + elapsed_seconds * rps)
+
+local allowed = 0
+local retry_after_ms = 0
+if tokens >= 1 then
+	allowed = 1
+	tokens = tokens - 1
+else
+	retry_after_ms = math.ceil((1 - tokens) / rps * 1000)
+end
+
+redis.call("HMSET", KEYS[1], tokens_key, tokens, updated_key, now)
+redis.call("EXPIRE", KEYS[1], ttl)
+
+return {allowed, retry_after_ms}
+`
+
+// RedisRateLimiter is a drop-in replacement for RateLimiter that shares
+// token-bucket state across every registry replica via Redis.
+type RedisRateLimiter struct {
+	client *redis.Client
+	script *redis.Script
+	config RateLimitConfig
+}
+
+// NewRedisRateLimiter builds a RedisRateLimiter and verifies connectivity to
+// the configured Redis instance.
+func NewRedisRateLimiter(ctx context.Context, redisCfg config.RedisConfig, rlConfig RateLimitConfig) (*RedisRateLimiter, error) {
+	client := redis.NewClient(&redis.Options{
+		Addr:     redisCfg.Addr,
+		Password: redisCfg.Password,
+		DB:       redisCfg.DB,
+	})
+
+	if err := client.Ping(ctx).Err(); err != nil {
+		return nil, fmt.Errorf("failed to connect to redis rate limiter backend: %w", err)
+	}
+
+	return &RedisRateLimiter{
+		client: client,
+		script: redis.NewScript(tokenBucketScript),
+		config: rlConfig,
+	}, nil
+}
+
+// Allow runs the token-bucket script for ip, returning whether the request
+// is allowed and, if not, how long the caller should wait before retrying.
+func (rl *RedisRateLimiter) Allow(ctx context.Context, ip string) (allowed bool, retryAfter time.Duration, err error) {
+	key := fmt.Sprintf("rl:%s", ip)
+
+	res, err := rl.script.Run(ctx, rl.client, []string{key},
+		rl.config.RequestsPerSecond,
+		rl.config.BurstSize,
+		time.Now().UnixNano(),
+		int64(rl.config.ExpiryTime.Seconds()),
+	).Slice()
+	if err != nil {
+		return false, 0, fmt.Errorf("rate limit script failed: %w", err)
+	}
+
+	allowedInt, _ := res[0].(int64)
+	retryAfterMs, _ := res[1].(int64)
+
+	return allowedInt == 1, time.Duration(retryAfterMs) * time.Millisecond, nil
+}
+
+// Handler returns the gin middleware backed by this rate limiter.
+func (rl *RedisRateLimiter) Handler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		allowed, retryAfter, err := rl.Allow(c.Request.Context(), c.ClientIP())
+		if err != nil {
+			// Fail open: a Redis blip shouldn't take the registry down.
+			c.Next()
+			return
+		}
+		if !allowed {
+			c.Header("Retry-After", fmt.Sprintf("%.0f", retryAfter.Seconds()))
+			c.JSON(429, gin.H{
+				"error":       "Too many requests",
+				"retry_after": retryAfter.String(),
+			})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
@@ -1,6 +1,7 @@
 package routes
 
 import (
+	"context"
 	"time"
 
 	"github.com/Parallels/pd-ai-agents-registry/internal/api/handlers"
@@ -9,6 +10,7 @@ import (
 	"github.com/Parallels/pd-ai-agents-registry/internal/db/mongodb"
 	"github.com/Parallels/pd-ai-agents-registry/internal/logger"
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 )
@@ -18,38 +20,70 @@ func NewRouter(cfg *config.Config, logger *logger.Logger, db *mongodb.Client) *g
 	router.Use(gin.Recovery())
 	router.Use(middleware.Logger(logger))
 
+	// Wraps every metadata lookup on the request path with the in-process
+	// LRU described in internal/db/mongodb/cache.go. Background workers
+	// (cmd/worker, cmd/cleanup, ...) keep using the raw db passed in above.
+	cachingDB := mongodb.NewCachingClient(db, cfg.Cache)
+
 	// Initialize handlers
-	h, err := handlers.NewHandler(cfg, logger, db)
+	h, err := handlers.NewHandler(cfg, logger, cachingDB)
 	if err != nil {
 		logger.Fatal("Failed to initialize handlers", err)
 	}
-	auth := middleware.NewAuthMiddleware(cfg.JWT.Secret)
+	auth := middleware.NewAuthMiddleware(h.Tokens(), cachingDB, cfg.Admin.Password)
+
+	// Pick up JWT expiry, admin password, and storage endpoint changes
+	// without a restart. Only file-backed config sources actually have
+	// anything to watch; remote/secret sources are a no-op here.
+	if err := config.Watch(context.Background(), h.SetConfig); err != nil {
+		logger.Error("Failed to start config watcher", "error", err)
+	}
 
-	// Configure rate limiter
-	downloadRateLimit := middleware.RateLimit(middleware.RateLimitConfig{
+	// Configure rate limiter. Multi-replica deployments share bucket state
+	// through Redis; a single-node deployment falls back to the in-memory
+	// limiter.
+	rateLimitConfig := middleware.RateLimitConfig{
 		RequestsPerSecond: 1,               // 1 request per second
 		BurstSize:         5,               // Allow bursts of up to 5 requests
 		ExpiryTime:        time.Minute * 5, // Clean up visitors after 5 minutes
-	})
+	}
+	downloadRateLimit := middleware.RateLimit(rateLimitConfig)
+	if cfg.Redis.Addr != "" {
+		redisLimiter, err := middleware.NewRedisRateLimiter(context.Background(), cfg.Redis, rateLimitConfig)
+		if err != nil {
+			logger.Error("Failed to connect to redis rate limiter, falling back to in-memory limiter", "error", err)
+		} else {
+			downloadRateLimit = redisLimiter.Handler()
+		}
+	}
 
 	// Swagger docs
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
+	// Prometheus scrape endpoint, exposing the metadata cache counters
+	// alongside the default Go/process collectors.
+	router.GET("/metrics", gin.WrapH(promhttp.Handler()))
+
 	// API v1 routes
 	v1 := router.Group("/api/v1")
 	{
 		// Public routes
 		v1.POST("/auth/login", h.Login)
 		v1.POST("/auth/register", h.Register)
+		v1.POST("/auth/refresh", h.Refresh)
+		v1.POST("/auth/logout", auth.JWT(), h.Logout)
+		v1.POST("/auth/logout-all", auth.JWT(), h.LogoutAll)
 
 		// Protected routes
 		packages := v1.Group("/packages")
 		packages.Use(auth.JWT())
 		{
 			packages.GET("", h.ListPackages)
+			packages.GET("/categories", h.ListCategories)
 			packages.GET("/:name", h.GetPackage)
 			packages.GET("/:name/versions", h.ListVersions)
 			packages.GET("/:name/versions/:version", h.GetVersion)
+			packages.GET("/:name/dist-tags", h.ListDistTags)
 
 			// Protected with API key
 			upload := packages.Group("")
@@ -57,12 +91,57 @@ func NewRouter(cfg *config.Config, logger *logger.Logger, db *mongodb.Client) *g
 			{
 				upload.POST("/:name/versions/:version/upload", h.UploadPackage)
 				upload.DELETE("/:name/versions/:version/:filename", h.DeletePackage)
+				upload.POST("/:name/versions/:version/reprocess", h.ReprocessVersion)
+				upload.POST("/:name/versions/:version/:filename/sign", h.SignPackageFile)
+				upload.PUT("/:name/dist-tags/:tag", h.SetDistTag)
+				upload.DELETE("/:name/dist-tags/:tag", h.RemoveDistTag)
+
+				// Version lifecycle: yank/unyank are soft, reversible, and
+				// publisher-level; purge is the hard delete previously done
+				// unconditionally, now gated on a prior yank plus a grace
+				// period (config.VersionsConfig).
+				upload.POST("/:name/versions/:version/yank", h.YankVersion)
+				upload.POST("/:name/versions/:version/unyank", h.UnyankVersion)
+				upload.DELETE("/:name/versions/:version/purge", h.PurgeVersion)
+
+				// Direct-to-storage multipart upload, for archives too
+				// large to buffer through the API server.
+				upload.POST("/:name/versions/:version/:filename/uploads", h.InitiateMultipartUpload)
+				upload.GET("/:name/versions/:version/:filename/uploads/:uploadId/parts/:partNumber", h.PresignUploadPart)
+				upload.POST("/:name/versions/:version/:filename/uploads/:uploadId/complete", h.CompleteMultipartUpload)
+
+				// Chunked, resumable uploads through the API server itself,
+				// for CLIs on unreliable links that need to pick an upload
+				// back up after a dropped connection.
+				upload.POST("/:name/versions/:version/:filename/resumable-uploads", h.StartBlobUpload)
+				upload.PATCH("/:name/versions/:version/:filename/resumable-uploads/:uploadId", h.AppendBlobUpload)
+				upload.GET("/:name/versions/:version/:filename/resumable-uploads/:uploadId", h.GetBlobUploadStatus)
+				upload.PUT("/:name/versions/:version/:filename/resumable-uploads/:uploadId", h.FinishBlobUpload)
 			}
 		}
 
 		// Download route (public with rate limiting)
 		v1.GET("/download/:name/:version/:filename", downloadRateLimit, h.DownloadPackage)
 
+		// Audit trail for package/version mutations (see mongodb.Client.LogAudit).
+		v1.GET("/audit-log", auth.JWT(), h.QueryAuditLog)
+
+		// Publisher key discovery for package signature verification.
+		router.GET("/.well-known/pd-agents-registry/trusted-keys.json", h.TrustedKeys)
+
+		// JWKS for verifying access tokens, when jwt.algorithm is RS256/ES256.
+		router.GET("/.well-known/jwks.json", h.JWKS)
+
+		// PEP 503/691 Simple Repository API, so agent packages can be
+		// installed with `pip install --index-url`
+		v1.GET("/simple/", downloadRateLimit, h.SimpleIndex)
+		v1.GET("/simple/:name/", downloadRateLimit, h.SimplePackage)
+
+		// Format-native repository indexes (apk, deb, rpm, cargo), so the
+		// registry can be pointed at directly by those package managers'
+		// own client tooling instead of only its JSON API.
+		router.GET("/repo/:format/*path", downloadRateLimit, h.GetRepositoryIndex)
+
 		// Update routes
 		updates := v1.Group("/updates")
 		{
@@ -70,7 +149,11 @@ func NewRouter(cfg *config.Config, logger *logger.Logger, db *mongodb.Client) *g
 			updates.GET("", h.ListUpdates)
 			updates.GET("/latest/:platform/:arch", h.GetLatestUpdate)
 			updates.GET("/download/:version/:platform/:arch/:filename", downloadRateLimit, h.DownloadUpdate)
+			updates.HEAD("/download/:version/:platform/:arch/:filename", downloadRateLimit, h.HeadUpdate)
 			updates.GET("/latest", h.GetLatestVersionInfo)
+			updates.GET("/latest/:platform/:arch/notes", h.GetLatestReleaseNotes)
+			updates.GET("/:version/notes", h.GetReleaseNotes)
+			updates.POST("/check", downloadRateLimit, h.CheckIn)
 
 			// Protected routes (admin only)
 			adminUpdates := updates.Group("")
@@ -78,6 +161,10 @@ func NewRouter(cfg *config.Config, logger *logger.Logger, db *mongodb.Client) *g
 			{
 				adminUpdates.POST("/:version/:platform/:arch", h.UploadUpdate)
 				adminUpdates.DELETE("/:version/:platform/:arch", h.DeleteUpdate)
+				adminUpdates.GET("/stats", h.InstanceStats)
+				adminUpdates.POST("/rollout/:channel/:platform/:arch", h.SetRolloutPercentage)
+				adminUpdates.POST("/rollout/:channel/:platform/:arch/blacklist", h.AddRolloutBlacklist)
+				adminUpdates.DELETE("/rollout/:channel/:platform/:arch/blacklist/:version", h.RemoveRolloutBlacklist)
 			}
 		}
 	}
@@ -0,0 +1,71 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"encoding/base64"
+)
+
+// JWK is a single public key in JWK format, as published at
+// /.well-known/jwks.json so downstream services can verify this
+// registry's access tokens without sharing a symmetric secret.
+type JWK struct {
+	Kty string `json:"kty"`
+	Use string `json:"use,omitempty"`
+	Kid string `json:"kid,omitempty"`
+	Alg string `json:"alg,omitempty"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+// JWKS is a JSON Web Key Set, the response body of the JWKS endpoint.
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS returns the public key set for this Issuer. ok is false for HS256
+// issuers, which have no public key to publish; the handler should
+// respond 404 in that case rather than serve an empty set.
+func (i *Issuer) JWKS() (JWKS, bool) {
+	switch key := i.verifyKey.(type) {
+	case *rsa.PublicKey:
+		return JWKS{Keys: []JWK{{
+			Kty: "RSA",
+			Use: "sig",
+			Kid: i.keyID,
+			Alg: "RS256",
+			N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(bigEndianBytes(key.E)),
+		}}}, true
+	case *ecdsa.PublicKey:
+		size := (key.Curve.Params().BitSize + 7) / 8
+		return JWKS{Keys: []JWK{{
+			Kty: "EC",
+			Use: "sig",
+			Kid: i.keyID,
+			Alg: "ES256",
+			Crv: "P-256",
+			X:   base64.RawURLEncoding.EncodeToString(key.X.FillBytes(make([]byte, size))),
+			Y:   base64.RawURLEncoding.EncodeToString(key.Y.FillBytes(make([]byte, size))),
+		}}}, true
+	default:
+		return JWKS{}, false
+	}
+}
+
+// bigEndianBytes encodes n (the RSA public exponent, conventionally 65537)
+// as the minimal big-endian byte string JWK's "e" field expects.
+func bigEndianBytes(n int) []byte {
+	if n == 0 {
+		return []byte{0}
+	}
+	var b []byte
+	for n > 0 {
+		b = append([]byte{byte(n & 0xff)}, b...)
+		n >>= 8
+	}
+	return b
+}
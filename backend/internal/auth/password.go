@@ -0,0 +1,108 @@
+// Package auth implements password hashing and JWT issuance/verification
+// for the registry's user accounts, shared by the login/register handlers
+// and the JWT auth middleware.
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Password hashing algorithm identifiers, recorded in models.User.PasswordAlgorithm.
+const (
+	AlgorithmBcrypt   = "bcrypt"
+	AlgorithmArgon2id = "argon2id"
+)
+
+// argon2Params are the cost parameters new passwords are hashed with.
+// Existing argon2id hashes embed their own parameters in the encoded
+// string, so changing these only affects passwords hashed from now on.
+type argon2Params struct {
+	memory      uint32
+	iterations  uint32
+	parallelism uint8
+	saltLength  uint32
+	keyLength   uint32
+}
+
+var defaultArgon2Params = argon2Params{
+	memory:      64 * 1024,
+	iterations:  3,
+	parallelism: 2,
+	saltLength:  16,
+	keyLength:   32,
+}
+
+// HashPassword hashes password with argon2id, encoding the salt and cost
+// parameters into the returned PHC-formatted string so VerifyPassword
+// doesn't need them passed back in separately.
+func HashPassword(password string) (string, error) {
+	p := defaultArgon2Params
+
+	salt := make([]byte, p.saltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("auth: failed to generate salt: %w", err)
+	}
+
+	hash := argon2.IDKey([]byte(password), salt, p.iterations, p.memory, p.parallelism, p.keyLength)
+
+	encoded := fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, p.memory, p.iterations, p.parallelism,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(hash))
+	return encoded, nil
+}
+
+// VerifyPassword reports whether password matches encoded, which may be
+// either an argon2id hash produced by HashPassword or a legacy
+// bcrypt-default-cost hash predating it.
+func VerifyPassword(encoded, password string) (bool, error) {
+	if strings.HasPrefix(encoded, "$argon2id$") {
+		return verifyArgon2id(encoded, password)
+	}
+	err := bcrypt.CompareHashAndPassword([]byte(encoded), []byte(password))
+	if err != nil {
+		if err == bcrypt.ErrMismatchedHashAndPassword {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+func verifyArgon2id(encoded, password string) (bool, error) {
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 {
+		return false, fmt.Errorf("auth: malformed argon2id hash")
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil {
+		return false, fmt.Errorf("auth: malformed argon2id version: %w", err)
+	}
+	if version != argon2.Version {
+		return false, fmt.Errorf("auth: unsupported argon2 version %d", version)
+	}
+
+	var p argon2Params
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &p.memory, &p.iterations, &p.parallelism); err != nil {
+		return false, fmt.Errorf("auth: malformed argon2id parameters: %w", err)
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return false, fmt.Errorf("auth: malformed argon2id salt: %w", err)
+	}
+	want, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return false, fmt.Errorf("auth: malformed argon2id hash: %w", err)
+	}
+
+	got := argon2.IDKey([]byte(password), salt, p.iterations, p.memory, p.parallelism, uint32(len(want)))
+	return subtle.ConstantTimeCompare(got, want) == 1, nil
+}
@@ -0,0 +1,190 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/Parallels/pd-ai-agents-registry/internal/config"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Claims is the payload carried by access tokens. Roles is denormalized
+// from the user document at issue time so the JWT middleware can
+// authorize requests without a database round trip.
+type Claims struct {
+	jwt.RegisteredClaims
+	Roles []string `json:"roles,omitempty"`
+}
+
+// Issuer signs and verifies access tokens and mints refresh tokens for a
+// single configured algorithm (HS256, RS256, or ES256). Refresh tokens
+// themselves aren't JWTs; see NewRefreshToken.
+type Issuer struct {
+	algorithm  string
+	keyID      string
+	signingKey interface{}
+	verifyKey  interface{}
+	accessTTL  time.Duration
+	refreshTTL time.Duration
+}
+
+// NewIssuer builds an Issuer from JWTConfig. HS256 (the default) signs and
+// verifies with cfg.Secret as a shared key; RS256/ES256 load a private key
+// from cfg.PrivateKeyPEM (preferred, since it may be a resolved secret
+// reference) or cfg.PrivateKeyPath and derive the public key to verify
+// with and to publish via JWKS.
+func NewIssuer(cfg config.JWTConfig) (*Issuer, error) {
+	issuer := &Issuer{
+		algorithm:  cfg.Algorithm,
+		keyID:      cfg.KeyID,
+		accessTTL:  time.Duration(cfg.ExpiryHour) * time.Hour,
+		refreshTTL: time.Duration(cfg.RefreshExpiryHour) * time.Hour,
+	}
+
+	switch cfg.Algorithm {
+	case "", "HS256":
+		issuer.algorithm = "HS256"
+		issuer.signingKey = []byte(cfg.Secret)
+		issuer.verifyKey = []byte(cfg.Secret)
+	case "RS256":
+		pemBytes, err := loadKeyPEM(cfg)
+		if err != nil {
+			return nil, err
+		}
+		key, err := jwt.ParseRSAPrivateKeyFromPEM(pemBytes)
+		if err != nil {
+			return nil, fmt.Errorf("auth: failed to parse RS256 private key: %w", err)
+		}
+		issuer.signingKey = key
+		issuer.verifyKey = &key.PublicKey
+	case "ES256":
+		pemBytes, err := loadKeyPEM(cfg)
+		if err != nil {
+			return nil, err
+		}
+		key, err := jwt.ParseECPrivateKeyFromPEM(pemBytes)
+		if err != nil {
+			return nil, fmt.Errorf("auth: failed to parse ES256 private key: %w", err)
+		}
+		issuer.signingKey = key
+		issuer.verifyKey = &key.PublicKey
+	default:
+		return nil, fmt.Errorf("auth: unsupported jwt algorithm %q", cfg.Algorithm)
+	}
+
+	return issuer, nil
+}
+
+// loadKeyPEM returns the PEM-encoded private key material for RS256/ES256,
+// preferring the inline PrivateKeyPEM (which may have come from Vault or
+// Secrets Manager) over reading PrivateKeyPath off disk.
+func loadKeyPEM(cfg config.JWTConfig) ([]byte, error) {
+	if cfg.PrivateKeyPEM != "" {
+		return []byte(cfg.PrivateKeyPEM), nil
+	}
+	if cfg.PrivateKeyPath == "" {
+		return nil, fmt.Errorf("auth: jwt.algorithm=%s requires private_key_pem or private_key_path", cfg.Algorithm)
+	}
+	data, err := os.ReadFile(cfg.PrivateKeyPath)
+	if err != nil {
+		return nil, fmt.Errorf("auth: failed to read %s: %w", cfg.PrivateKeyPath, err)
+	}
+	return data, nil
+}
+
+func (i *Issuer) signingMethod() jwt.SigningMethod {
+	switch i.algorithm {
+	case "RS256":
+		return jwt.SigningMethodRS256
+	case "ES256":
+		return jwt.SigningMethodES256
+	default:
+		return jwt.SigningMethodHS256
+	}
+}
+
+// IssueAccessToken signs a new access token for userID, returning the
+// token string along with its jti so the caller can persist it if it ever
+// needs to be revoked before expiry.
+func (i *Issuer) IssueAccessToken(userID string, roles []string) (tokenString, jti string, expiresAt time.Time, err error) {
+	now := time.Now()
+	expiresAt = now.Add(i.accessTTL)
+	jti = newJTI()
+
+	token := jwt.NewWithClaims(i.signingMethod(), Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   userID,
+			ID:        jti,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+		Roles: roles,
+	})
+	if i.keyID != "" {
+		token.Header["kid"] = i.keyID
+	}
+
+	tokenString, err = token.SignedString(i.signingKey)
+	if err != nil {
+		return "", "", time.Time{}, fmt.Errorf("auth: failed to sign access token: %w", err)
+	}
+	return tokenString, jti, expiresAt, nil
+}
+
+// ParseAccessToken verifies tokenString's signature and expiry and returns
+// its claims. It does not check the JTI denylist; callers (the JWT
+// middleware) do that separately against the database.
+func (i *Issuer) ParseAccessToken(tokenString string) (*Claims, error) {
+	claims := &Claims{}
+	_, err := jwt.ParseWithClaims(tokenString, claims, func(t *jwt.Token) (interface{}, error) {
+		if t.Method != i.signingMethod() {
+			return nil, fmt.Errorf("auth: unexpected signing method %v", t.Header["alg"])
+		}
+		return i.verifyKey, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// RefreshTTL is how long newly issued refresh tokens remain valid.
+func (i *Issuer) RefreshTTL() time.Duration {
+	return i.refreshTTL
+}
+
+// NewRefreshToken generates an opaque refresh token. Only its sha256 hash
+// (HashRefreshToken) is ever persisted, so the plaintext token is returned
+// once to the caller to hand back to the client and never stored.
+func NewRefreshToken() (token, hash string, err error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", "", fmt.Errorf("auth: failed to generate refresh token: %w", err)
+	}
+	token = base64.RawURLEncoding.EncodeToString(raw)
+	return token, HashRefreshToken(token), nil
+}
+
+// HashRefreshToken returns the sha256 hash of a refresh token, as stored in
+// the refresh_tokens collection.
+func HashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// newJTI generates a random token identifier used both as the JWT "jti"
+// claim and as the key for the revocation denylist.
+func newJTI() string {
+	raw := make([]byte, 16)
+	if _, err := rand.Read(raw); err != nil {
+		// crypto/rand.Read only fails if the OS CSPRNG is unavailable, which
+		// would already be fatal for every other use of it in this process.
+		panic(fmt.Sprintf("auth: failed to generate jti: %v", err))
+	}
+	return hex.EncodeToString(raw)
+}
@@ -0,0 +1,47 @@
+package blobupload
+
+import (
+	"context"
+	"time"
+
+	"github.com/Parallels/pd-ai-agents-registry/internal/chunkstore"
+	"github.com/Parallels/pd-ai-agents-registry/internal/db/mongodb"
+	"github.com/Parallels/pd-ai-agents-registry/internal/logger"
+	"github.com/Parallels/pd-ai-agents-registry/internal/worker"
+)
+
+// Sweep cancels every upload session that expired before now, freeing any
+// chunks it wrote to storage that no finished upload ever came to retain. A
+// chunk's storage object is only enqueued for deletion when no chunk
+// document references it, since the same content may have already been
+// retained by a different, successful upload (chunks are deduplicated
+// across sessions, packages, and versions). It returns the number of
+// sessions swept.
+func Sweep(ctx context.Context, db *mongodb.Client, tasks *worker.Client, log *logger.Logger) (int, error) {
+	sessions, err := db.ListExpiredBlobUploads(ctx, time.Now())
+	if err != nil {
+		return 0, err
+	}
+
+	for _, session := range sessions {
+		for _, hash := range session.ChunkHashes {
+			retained, err := db.ChunkRetained(ctx, hash)
+			if err != nil {
+				log.Error("Failed to check chunk retention", "error", err, "hash", hash, "session", session.ID)
+				continue
+			}
+			if retained {
+				continue
+			}
+			if err := tasks.EnqueueDeleteBlob(ctx, worker.DeleteBlobPayload{FileKey: chunkstore.ChunkKey(hash)}); err != nil {
+				log.Error("Failed to enqueue orphaned chunk deletion", "error", err, "hash", hash, "session", session.ID)
+			}
+		}
+
+		if err := db.CancelBlobUpload(ctx, session.ID); err != nil {
+			log.Error("Failed to cancel expired upload session", "error", err, "session", session.ID)
+		}
+	}
+
+	return len(sessions), nil
+}
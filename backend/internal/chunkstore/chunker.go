@@ -0,0 +1,169 @@
+// Package chunkstore splits uploaded files into content-defined chunks and
+// stores them under a content-addressed layout, so identical blocks shared
+// across package versions (or even across unrelated packages) are only
+// written to the storage backend once.
+package chunkstore
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"path"
+
+	"github.com/Parallels/pd-ai-agents-registry/internal/storage"
+)
+
+const (
+	// minChunkSize and maxChunkSize bound every chunk but the last, which
+	// may be shorter. Sizes mirror restic's defaults, which in turn are
+	// chosen so the rolling hash gets enough bytes to find a good boundary
+	// without quantizing large files into too many round trips.
+	minChunkSize = 1 << 20 // 1 MiB
+	maxChunkSize = 8 << 20 // 8 MiB
+
+	// cutMask is tested against the rolling gear hash once minChunkSize has
+	// been read; its popcount sets the average chunk size (2^21 = 2 MiB).
+	cutMask = 1<<21 - 1
+)
+
+// gearTable assigns a pseudo-random 64-bit weight to every byte value. The
+// rolling hash shifts in the previous state and adds the weight of the new
+// byte, so a run of identical bytes doesn't collapse the hash the way a
+// simple sum would.
+var gearTable = buildGearTable()
+
+func buildGearTable() [256]uint64 {
+	// splitmix64, seeded with a fixed constant so the table (and therefore
+	// chunk boundaries) are stable across restarts and replicas.
+	var table [256]uint64
+	state := uint64(0x9e3779b97f4a7c15)
+	for i := range table {
+		state += 0x9e3779b97f4a7c15
+		z := state
+		z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+		z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+		table[i] = z ^ (z >> 31)
+	}
+	return table
+}
+
+// ChunkKey returns the storage key a chunk with the given sha256 hex digest
+// is stored under, fanned out by its first byte to keep any one directory
+// from growing unbounded.
+func ChunkKey(hash string) string {
+	return path.Join("chunks", hash[:2], hash)
+}
+
+// Chunk describes one piece written by Split: its content address and size.
+type Chunk struct {
+	Hash string
+	Size int64
+}
+
+// Split reads r to EOF, cutting it into content-defined chunks and writing
+// each one to backend at ChunkKey(hash) unless it's already present. It
+// returns the ordered list of chunks (a file whose content repeats a block
+// contains that hash more than once) needed to reassemble the file.
+func Split(ctx context.Context, backend storage.Backend, r io.Reader) ([]Chunk, error) {
+	var chunks []Chunk
+	buf := make([]byte, 0, maxChunkSize)
+	reader := &byteReader{r: r}
+
+	for {
+		buf = buf[:0]
+		var hash uint64
+		for {
+			b, ok, err := reader.readByte()
+			if err != nil {
+				return nil, fmt.Errorf("chunkstore: failed to read upload: %w", err)
+			}
+			if !ok {
+				break
+			}
+			buf = append(buf, b)
+			hash = (hash << 1) + gearTable[b]
+			if len(buf) >= maxChunkSize {
+				break
+			}
+			if len(buf) >= minChunkSize && hash&cutMask == 0 {
+				break
+			}
+		}
+		if len(buf) == 0 {
+			break
+		}
+
+		chunkHash, err := putChunk(ctx, backend, buf)
+		if err != nil {
+			return nil, err
+		}
+		chunks = append(chunks, Chunk{Hash: chunkHash, Size: int64(len(buf))})
+
+		if len(buf) < maxChunkSize && reader.eof && reader.pos >= reader.n {
+			break
+		}
+	}
+
+	return chunks, nil
+}
+
+// putChunk uploads buf under its content address, skipping backends that
+// already have it, and returns the hex sha256 digest used as its key.
+func putChunk(ctx context.Context, backend storage.Backend, buf []byte) (string, error) {
+	sum := sha256.Sum256(buf)
+	hash := fmt.Sprintf("%x", sum)
+	key := ChunkKey(hash)
+
+	exists, err := backend.Exists(ctx, key)
+	if err != nil {
+		return "", fmt.Errorf("chunkstore: failed to check chunk %s: %w", hash, err)
+	}
+	if exists {
+		return hash, nil
+	}
+
+	// Copy buf since Put may retain the reader beyond this call.
+	data := make([]byte, len(buf))
+	copy(data, buf)
+	if _, _, err := backend.Put(ctx, key, bytes.NewReader(data), "application/octet-stream"); err != nil {
+		return "", fmt.Errorf("chunkstore: failed to store chunk %s: %w", hash, err)
+	}
+	return hash, nil
+}
+
+// byteReader adapts an io.Reader to single-byte reads via a small internal
+// buffer, since the chunker's rolling hash needs one byte at a time.
+type byteReader struct {
+	r   io.Reader
+	buf [32 * 1024]byte
+	pos int
+	n   int
+	eof bool
+}
+
+func (b *byteReader) readByte() (byte, bool, error) {
+	if b.pos >= b.n {
+		if b.eof {
+			return 0, false, nil
+		}
+		n, err := b.r.Read(b.buf[:])
+		b.pos, b.n = 0, n
+		if n == 0 {
+			if err == io.EOF || err == nil {
+				b.eof = true
+				return 0, false, nil
+			}
+			return 0, false, err
+		}
+		if err == io.EOF {
+			b.eof = true
+		} else if err != nil {
+			return 0, false, err
+		}
+	}
+	c := b.buf[b.pos]
+	b.pos++
+	return c, true, nil
+}
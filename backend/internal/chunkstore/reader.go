@@ -0,0 +1,59 @@
+package chunkstore
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/Parallels/pd-ai-agents-registry/internal/storage"
+)
+
+// Open returns a reader that reassembles a file from its ordered chunk
+// hashes, fetching (and closing) one chunk from backend at a time so a
+// large file never needs to be buffered in full.
+func Open(ctx context.Context, backend storage.Backend, chunkHashes []string) (io.ReadCloser, error) {
+	return &chunkReader{ctx: ctx, backend: backend, hashes: chunkHashes}, nil
+}
+
+type chunkReader struct {
+	ctx     context.Context
+	backend storage.Backend
+	hashes  []string
+	current io.ReadCloser
+}
+
+func (c *chunkReader) Read(p []byte) (int, error) {
+	for {
+		if c.current == nil {
+			if len(c.hashes) == 0 {
+				return 0, io.EOF
+			}
+			hash := c.hashes[0]
+			c.hashes = c.hashes[1:]
+
+			r, err := c.backend.Get(c.ctx, ChunkKey(hash))
+			if err != nil {
+				return 0, fmt.Errorf("chunkstore: failed to fetch chunk %s: %w", hash, err)
+			}
+			c.current = r
+		}
+
+		n, err := c.current.Read(p)
+		if err == io.EOF {
+			c.current.Close()
+			c.current = nil
+			if n > 0 {
+				return n, nil
+			}
+			continue
+		}
+		return n, err
+	}
+}
+
+func (c *chunkReader) Close() error {
+	if c.current != nil {
+		return c.current.Close()
+	}
+	return nil
+}
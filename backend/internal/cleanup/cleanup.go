@@ -0,0 +1,221 @@
+// Package cleanup implements the retention worker that prunes old package
+// versions so storage doesn't grow unbounded. Rules are configured via
+// config.CleanupConfig: a default keep-last-N/max-age policy plus
+// per-package overrides, with glob/regex patterns to pin specific versions
+// that must never be pruned. Run reports what it did (or, in dry-run mode,
+// what it would do) as a Summary of per-version Events for observability.
+package cleanup
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"time"
+
+	"github.com/Parallels/pd-ai-agents-registry/internal/chunkstore"
+	"github.com/Parallels/pd-ai-agents-registry/internal/config"
+	"github.com/Parallels/pd-ai-agents-registry/internal/db/mongodb"
+	"github.com/Parallels/pd-ai-agents-registry/internal/logger"
+	"github.com/Parallels/pd-ai-agents-registry/internal/models"
+	"github.com/Parallels/pd-ai-agents-registry/internal/worker"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const packagesPageSize = 100
+
+// Action is the outcome recorded for a single version considered during a
+// Run.
+type Action string
+
+const (
+	ActionDeleted Action = "deleted"
+	ActionKept    Action = "kept"
+	ActionSkipped Action = "skipped"
+)
+
+// Event is one version's retention decision, emitted for observability.
+type Event struct {
+	PackageName string `json:"package_name"`
+	Version     string `json:"version"`
+	Action      Action `json:"action"`
+	Reason      string `json:"reason"`
+}
+
+// Summary totals a Run's events.
+type Summary struct {
+	Deleted int     `json:"deleted"`
+	Kept    int     `json:"kept"`
+	Skipped int     `json:"skipped"`
+	Events  []Event `json:"events"`
+}
+
+// Cleanup prunes old package versions according to cfg's retention rules.
+type Cleanup struct {
+	db     *mongodb.Client
+	tasks  *worker.Client
+	logger *logger.Logger
+	cfg    config.CleanupConfig
+}
+
+// NewCleanup builds a Cleanup. tasks may be nil, in which case orphaned
+// blobs are never enqueued for garbage collection (useful for dry runs that
+// don't have a Redis connection available).
+func NewCleanup(db *mongodb.Client, tasks *worker.Client, log *logger.Logger, cfg config.CleanupConfig) *Cleanup {
+	return &Cleanup{db: db, tasks: tasks, logger: log, cfg: cfg}
+}
+
+// Run walks every package and prunes versions that fall outside its
+// retention rule. When dryRun is true, no version is deleted and no blob GC
+// is enqueued; every candidate that would be pruned is instead recorded as a
+// "deleted" event whose reason marks it as a dry run.
+func (cl *Cleanup) Run(ctx context.Context, dryRun bool) (Summary, error) {
+	var summary Summary
+
+	for page := 1; ; page++ {
+		packages, total, err := cl.db.ListPackages(ctx, mongodb.ListPackagesFilter{Page: page, PerPage: packagesPageSize})
+		if err != nil {
+			return summary, fmt.Errorf("failed to list packages: %w", err)
+		}
+
+		for _, pkg := range packages {
+			if err := cl.runPackage(ctx, pkg, dryRun, &summary); err != nil {
+				return summary, fmt.Errorf("failed to clean up package %q: %w", pkg.Name, err)
+			}
+		}
+
+		if int64(page*packagesPageSize) >= total || len(packages) == 0 {
+			break
+		}
+	}
+
+	return summary, nil
+}
+
+// runPackage applies pkg's resolved rule and records one Event per version
+// it keeps, skips, or deletes.
+func (cl *Cleanup) runPackage(ctx context.Context, pkg models.Package, dryRun bool, summary *Summary) error {
+	rule := ruleFor(cl.cfg, pkg.Name)
+	if rule.KeepLastN <= 0 && rule.MaxAge <= 0 {
+		return nil
+	}
+
+	candidates := map[primitive.ObjectID]models.Version{}
+
+	if rule.KeepLastN > 0 {
+		beyond, err := cl.db.ListVersionsBeyondLast(ctx, pkg.ID, rule.KeepLastN)
+		if err != nil {
+			return fmt.Errorf("failed to list versions beyond keep_last_n: %w", err)
+		}
+		for _, v := range beyond {
+			candidates[v.ID] = v
+		}
+	}
+
+	if rule.MaxAge > 0 {
+		older, err := cl.db.ListVersionsOlderThan(ctx, pkg.ID, time.Now().Add(-rule.MaxAge))
+		if err != nil {
+			return fmt.Errorf("failed to list versions older than max_age: %w", err)
+		}
+		if rule.KeepLastN <= 0 {
+			// No keep-last-N protection configured: age alone decides.
+			for _, v := range older {
+				candidates[v.ID] = v
+			}
+		} else {
+			// Both rules configured: a version is only pruned once it falls
+			// outside the keep-last-N window AND is older than max_age.
+			olderIDs := make(map[primitive.ObjectID]struct{}, len(older))
+			for _, v := range older {
+				olderIDs[v.ID] = struct{}{}
+			}
+			for id := range candidates {
+				if _, stillOld := olderIDs[id]; !stillOld {
+					delete(candidates, id)
+				}
+			}
+		}
+	}
+
+	// Every version that isn't a pruning candidate is kept.
+	if err := cl.db.StreamVersions(ctx, pkg.ID, func(v models.Version) error {
+		if _, pruned := candidates[v.ID]; !pruned {
+			summary.Kept++
+			summary.Events = append(summary.Events, Event{PackageName: pkg.Name, Version: v.Version, Action: ActionKept, Reason: "within retention rule"})
+		}
+		return nil
+	}); err != nil {
+		return fmt.Errorf("failed to list kept versions: %w", err)
+	}
+
+	var toDelete []models.Version
+	for _, v := range candidates {
+		if rule.excludes(v.Version) {
+			summary.Skipped++
+			summary.Events = append(summary.Events, Event{PackageName: pkg.Name, Version: v.Version, Action: ActionSkipped, Reason: "matches exclude pattern"})
+			cl.logger.Info("cleanup: version excluded from pruning", "package", pkg.Name, "version", v.Version)
+			continue
+		}
+		toDelete = append(toDelete, v)
+	}
+
+	if dryRun {
+		for _, v := range toDelete {
+			summary.Deleted++
+			summary.Events = append(summary.Events, Event{PackageName: pkg.Name, Version: v.Version, Action: ActionDeleted, Reason: "dry-run: would be pruned"})
+			cl.logger.Info("cleanup: version would be pruned (dry-run)", "package", pkg.Name, "version", v.Version)
+		}
+		return nil
+	}
+
+	if len(toDelete) == 0 {
+		return nil
+	}
+
+	ids := make([]primitive.ObjectID, len(toDelete))
+	for i, v := range toDelete {
+		ids[i] = v.ID
+	}
+	deleted, err := cl.db.DeleteVersionsBulk(ctx, ids)
+	if err != nil {
+		return fmt.Errorf("failed to delete versions: %w", err)
+	}
+	summary.Deleted += int(deleted)
+
+	for _, v := range toDelete {
+		summary.Events = append(summary.Events, Event{PackageName: pkg.Name, Version: v.Version, Action: ActionDeleted, Reason: "pruned by retention rule"})
+		cl.logger.Info("cleanup: version pruned", "package", pkg.Name, "version", v.Version)
+		cl.gcVersionBlobs(ctx, pkg, v)
+	}
+
+	return nil
+}
+
+// gcVersionBlobs enqueues deletion of every blob a pruned version referenced,
+// mirroring the chunk-release/blob-delete pattern DeletePackage uses. Enqueue
+// failures are logged rather than returned since the version is already
+// gone; the worst case is an orphaned blob, not an inconsistent database.
+func (cl *Cleanup) gcVersionBlobs(ctx context.Context, pkg models.Package, v models.Version) {
+	if cl.tasks == nil {
+		return
+	}
+
+	for _, f := range v.Files {
+		if len(f.Chunks) > 0 {
+			drained, err := cl.db.ReleaseChunks(ctx, f.Chunks)
+			if err != nil {
+				cl.logger.Error("cleanup: failed to release chunks", "error", err, "package", pkg.Name, "version", v.Version)
+				continue
+			}
+			for _, hash := range drained {
+				if err := cl.tasks.EnqueueDeleteBlob(ctx, worker.DeleteBlobPayload{FileKey: chunkstore.ChunkKey(hash)}); err != nil {
+					cl.logger.Error("cleanup: failed to enqueue chunk deletion", "error", err, "package", pkg.Name, "version", v.Version)
+				}
+			}
+			continue
+		}
+		fileKey := path.Join("packages", pkg.Name, v.Version, f.Name)
+		if err := cl.tasks.EnqueueDeleteBlob(ctx, worker.DeleteBlobPayload{FileKey: fileKey}); err != nil {
+			cl.logger.Error("cleanup: failed to enqueue blob deletion", "error", err, "package", pkg.Name, "version", v.Version)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package cleanup
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/Parallels/pd-ai-agents-registry/internal/config"
+)
+
+// Rule describes the retention policy applied to a single package's
+// versions. The zero value keeps every version forever.
+type Rule struct {
+	// KeepLastN always preserves the N most recently created versions,
+	// regardless of age. Zero disables this protection.
+	KeepLastN int
+	// MaxAge prunes versions older than this, once they also fall outside
+	// KeepLastN's protection. Zero disables age-based pruning.
+	MaxAge time.Duration
+	// Exclude lists glob patterns (matched via filepath.Match) or
+	// "regex:"-prefixed regular expressions; a version matching any of them
+	// is never pruned.
+	Exclude []string
+}
+
+// ruleFor resolves the effective Rule for a package, merging cfg's default
+// rule with any package_rules override.
+func ruleFor(cfg config.CleanupConfig, packageName string) Rule {
+	rule := Rule{
+		KeepLastN: cfg.KeepLastN,
+		Exclude:   cfg.Exclude,
+	}
+	if cfg.MaxAgeDays > 0 {
+		rule.MaxAge = time.Duration(cfg.MaxAgeDays) * 24 * time.Hour
+	}
+
+	override, ok := cfg.PackageRules[packageName]
+	if !ok {
+		return rule
+	}
+	if override.KeepLastN > 0 {
+		rule.KeepLastN = override.KeepLastN
+	}
+	if override.MaxAgeDays > 0 {
+		rule.MaxAge = time.Duration(override.MaxAgeDays) * 24 * time.Hour
+	}
+	if len(override.Exclude) > 0 {
+		rule.Exclude = append(append([]string{}, rule.Exclude...), override.Exclude...)
+	}
+	return rule
+}
+
+// excludes reports whether version is pinned against pruning by one of the
+// rule's exclusion patterns.
+func (r Rule) excludes(version string) bool {
+	for _, pattern := range r.Exclude {
+		if rx, ok := strings.CutPrefix(pattern, "regex:"); ok {
+			re, err := regexp.Compile(rx)
+			if err != nil {
+				continue
+			}
+			if re.MatchString(version) {
+				return true
+			}
+			continue
+		}
+		if ok, err := filepath.Match(pattern, version); err == nil && ok {
+			return true
+		}
+	}
+	return false
+}
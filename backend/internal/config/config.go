@@ -3,17 +3,46 @@ package config
 import (
 	"fmt"
 	"log"
+	"os"
+	"strings"
 
 	"github.com/spf13/viper"
+	_ "github.com/spf13/viper/remote"
 )
 
+// configSource selects where Load reads its configuration from, via the
+// CONFIG_SOURCE env var. "file" (the default) keeps the historical
+// .env-or-environment behavior; the remote sources let deployments that
+// already run Consul/etcd for service config point the registry at it
+// instead of templating a file onto disk.
+const (
+	configSourceFile   = "file"
+	configSourceConsul = "consul"
+	configSourceEtcd   = "etcd"
+	configSourceVault  = "vault"
+	configSourceAWSSM  = "awssm"
+)
+
+// activeViper retains the *viper.Viper built by the most recent Load call,
+// so Watch can attach a file watcher to the same instance without Load
+// needing to change its return signature.
+var activeViper *viper.Viper
+
 type Config struct {
-	AppEnv  string        `mapstructure:"app_env"`
-	Server  ServerConfig  `mapstructure:"server"`
-	MongoDB MongoDBConfig `mapstructure:"mongodb"`
-	JWT     JWTConfig     `mapstructure:"jwt"`
-	S3      S3Config      `mapstructure:"s3"`
-	Admin   AdminConfig   `mapstructure:"admin"`
+	AppEnv   string         `mapstructure:"app_env"`
+	Server   ServerConfig   `mapstructure:"server"`
+	MongoDB  MongoDBConfig  `mapstructure:"mongodb"`
+	JWT      JWTConfig      `mapstructure:"jwt"`
+	S3       S3Config       `mapstructure:"s3"`
+	Storage  StorageConfig  `mapstructure:"storage"`
+	Redis    RedisConfig    `mapstructure:"redis"`
+	Admin    AdminConfig    `mapstructure:"admin"`
+	Signing  SigningConfig  `mapstructure:"signing"`
+	Cleanup  CleanupConfig  `mapstructure:"cleanup"`
+	Uploads  UploadsConfig  `mapstructure:"uploads"`
+	Registry RegistryConfig `mapstructure:"registry"`
+	Cache    CacheConfig    `mapstructure:"cache"`
+	Versions VersionsConfig `mapstructure:"versions"`
 }
 
 func (c *Config) GetBaseURL() string {
@@ -39,9 +68,19 @@ type MongoDBConfig struct {
 	Database string `mapstructure:"database"`
 }
 
+// JWTConfig controls access-token signing and the lifetime of refresh
+// tokens. Algorithm defaults to "HS256" (Secret as a shared symmetric key)
+// so existing deployments keep working unchanged; "RS256"/"ES256" sign
+// with PrivateKeyPath/PrivateKeyPEM instead and publish the matching public
+// key at /.well-known/jwks.json.
 type JWTConfig struct {
-	Secret     string `mapstructure:"secret"`
-	ExpiryHour int    `mapstructure:"expiry_hour"`
+	Secret            string `mapstructure:"secret"`
+	ExpiryHour        int    `mapstructure:"expiry_hour"`
+	RefreshExpiryHour int    `mapstructure:"refresh_expiry_hour"`
+	Algorithm         string `mapstructure:"algorithm"`
+	PrivateKeyPath    string `mapstructure:"private_key_path"`
+	PrivateKeyPEM     string `mapstructure:"private_key_pem"`
+	KeyID             string `mapstructure:"key_id"`
 }
 
 type S3Config struct {
@@ -52,38 +91,232 @@ type S3Config struct {
 	Endpoint        string `mapstructure:"endpoint"`
 }
 
+// StorageConfig selects and configures the object-storage backend used to
+// persist package and update artifacts. Type defaults to "s3" so existing
+// deployments keep working unchanged. Only the block matching Type is
+// validated at startup; self-hosted/air-gapped deployments that can't reach
+// AWS never need S3 credentials.
+type StorageConfig struct {
+	Type       string           `mapstructure:"type"`
+	Filesystem FilesystemConfig `mapstructure:"filesystem"`
+	S3         S3Config         `mapstructure:"s3"`
+	MinIO      MinIOConfig      `mapstructure:"minio"`
+	GCS        GCSConfig        `mapstructure:"gcs"`
+	Azure      AzureConfig      `mapstructure:"azure"`
+	Encryption EncryptionConfig `mapstructure:"encryption"`
+}
+
+// EncryptionConfig selects the trust boundary artifacts are encrypted
+// under at rest. Mode defaults to "none". "sse-s3"/"sse-kms" delegate to
+// the S3 backend's native server-side encryption; "client-side" encrypts
+// before the bytes ever leave the registry, wrapping a per-object data key
+// with the configured master key.
+type EncryptionConfig struct {
+	Mode        string `mapstructure:"mode"`
+	KMSProvider string `mapstructure:"kms_provider"`
+	KMSKeyID    string `mapstructure:"kms_key_id"`
+	// LocalMasterKey is a base64-encoded 32-byte AES-256 key used to wrap
+	// data keys when kms_provider is "local", for deployments without
+	// access to a cloud KMS. It is resolvable as a vault:///awssm:// secret
+	// reference like the other secret-bearing fields.
+	LocalMasterKey string `mapstructure:"local_master_key"`
+}
+
+type FilesystemConfig struct {
+	BaseDir string `mapstructure:"base_dir"`
+}
+
+// MinIOConfig configures the native minio-go driver, used instead of the
+// S3-compatibility driver when a deployment wants MinIO-specific behavior
+// like plain-HTTP endpoints or region-less buckets.
+type MinIOConfig struct {
+	Endpoint        string `mapstructure:"endpoint"`
+	Region          string `mapstructure:"region"`
+	Bucket          string `mapstructure:"bucket"`
+	AccessKeyID     string `mapstructure:"access_key_id"`
+	SecretAccessKey string `mapstructure:"secret_access_key"`
+	UseHTTP         bool   `mapstructure:"use_http"`
+}
+
+// GCSConfig configures the Google Cloud Storage driver. CredentialsFile may
+// be left empty to use application-default credentials (e.g. workload
+// identity); it's required to generate signed URLs, which need a service
+// account key to sign with.
+type GCSConfig struct {
+	Bucket          string `mapstructure:"bucket"`
+	CredentialsFile string `mapstructure:"credentials_file"`
+}
+
+// AzureConfig configures the Azure Blob Storage driver.
+type AzureConfig struct {
+	AccountName string `mapstructure:"account_name"`
+	AccountKey  string `mapstructure:"account_key"`
+	Container   string `mapstructure:"container"`
+}
+
+// RedisConfig configures the connection used for the asynq task queue that
+// backs the async upload-processing pipeline.
+type RedisConfig struct {
+	Addr     string `mapstructure:"addr"`
+	Password string `mapstructure:"password"`
+	DB       int    `mapstructure:"db"`
+}
+
 type AdminConfig struct {
 	Password string `mapstructure:"password"`
 }
 
+// SigningConfig lists the publisher public keys the registry trusts for
+// package signature verification, keyed by an opaque key ID. Keys are
+// hex-encoded ed25519 public keys; there is no single-key env binding since
+// this is a map, so it is only populated from the config file.
+type SigningConfig struct {
+	TrustedKeys map[string]string `mapstructure:"trusted_keys"`
+}
+
+// RegistryConfig controls the format-native repository index generators in
+// internal/registry, served under /repo/{format}/.... APKSigningKeyPEM is
+// the PEM-encoded RSA private key APKINDEX.tar.gz is signed with, the way
+// `abuild-sign` signs a real Alpine repository; APKKeyName is the file name
+// apk expects the matching public key under (e.g. "registry@example.com").
+// Leaving APKSigningKeyPEM empty disables the apk format, since an
+// unsigned APKINDEX is rejected by apk by default.
+type RegistryConfig struct {
+	APKSigningKeyPEM string `mapstructure:"apk_signing_key_pem"`
+	APKKeyName       string `mapstructure:"apk_key_name"`
+}
+
+// CleanupConfig controls the retention worker that prunes old package
+// versions (see internal/cleanup). KeepLastN and MaxAgeDays define the
+// default rule applied to every package; PackageRules overrides them for
+// individual packages by name. Disabled by default so operators can run the
+// cleanup CLI's dry-run mode before turning the scheduled worker on.
+type CleanupConfig struct {
+	Enabled      bool                   `mapstructure:"enabled"`
+	IntervalHour int                    `mapstructure:"interval_hour"`
+	KeepLastN    int                    `mapstructure:"keep_last_n"`
+	MaxAgeDays   int                    `mapstructure:"max_age_days"`
+	// Exclude lists glob patterns (or "regex:"-prefixed regular expressions)
+	// matched against a version string; a matching version is never pruned
+	// by the default rule.
+	Exclude      []string               `mapstructure:"exclude"`
+	PackageRules map[string]CleanupRule `mapstructure:"package_rules"`
+}
+
+// CleanupRule overrides the default retention rule for a single package.
+// Zero fields fall back to CleanupConfig's default.
+type CleanupRule struct {
+	KeepLastN  int      `mapstructure:"keep_last_n"`
+	MaxAgeDays int      `mapstructure:"max_age_days"`
+	Exclude    []string `mapstructure:"exclude"`
+}
+
+// UploadsConfig controls resumable upload sessions (see internal/blobupload).
+// SessionTTLHour bounds how long a client has to finish or resume an upload
+// before it's eligible for the sweeper to cancel.
+type UploadsConfig struct {
+	SessionTTLHour int `mapstructure:"session_ttl_hour"`
+}
+
 type ServerConfig struct {
 	Port   string `mapstructure:"port"`
 	Host   string `mapstructure:"host"`
 	Scheme string `mapstructure:"scheme"`
 }
 
+// CacheConfig controls the in-process LRU caches in front of
+// mongodb.Client.GetPackage/GetVersion/ListVersions (see
+// mongodb.CachingClient), which keeps repeated metadata lookups -- a
+// package manager fetches them on every install -- off the MongoDB hot
+// path. Package and version lookups are sized and expired independently,
+// since a deployment with a handful of huge packages and thousands of
+// versions each wants a very different balance than the reverse.
+type CacheConfig struct {
+	PackageSize       int `mapstructure:"package_size"`
+	PackageTTLSeconds int `mapstructure:"package_ttl_seconds"`
+	VersionSize       int `mapstructure:"version_size"`
+	VersionTTLSeconds int `mapstructure:"version_ttl_seconds"`
+	// NegativeTTLSeconds caches a "not found" result for this long, so a
+	// client repeatedly probing a package or version that doesn't exist
+	// doesn't hit MongoDB on every request. 0 (unset) defaults to 5 seconds;
+	// a negative value disables negative caching.
+	NegativeTTLSeconds int `mapstructure:"negative_ttl_seconds"`
+}
+
+// VersionsConfig controls yanking and hard-deletion of package versions
+// (see mongodb.Client.YankVersion/PurgeVersion). PurgeGraceHours is how long
+// a version must have been yanked before PurgeVersion will hard-delete it,
+// giving downstream mirrors and lockfiles time to notice the yank first.
+type VersionsConfig struct {
+	PurgeGraceHours int `mapstructure:"purge_grace_hours"`
+}
+
 func Load() (*Config, error) {
 	// Reset Viper to ensure clean state
 	viper.Reset()
 	v := viper.New()
 
-	// Set up Viper
-	v.SetConfigName(".env")
-	v.SetConfigType("env")
-	v.AddConfigPath(".")
-	v.AddConfigPath("./config")
-	v.AddConfigPath("../")
-
 	// Enable environment variables
 	v.AutomaticEnv()
 	v.SetEnvPrefix("")
 
-	// First read the config file
-	if err := v.ReadInConfig(); err != nil {
-		if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
-			return nil, fmt.Errorf("error reading config file: %w", err)
+	source := os.Getenv("CONFIG_SOURCE")
+	if source == "" {
+		source = configSourceFile
+	}
+
+	switch source {
+	case configSourceConsul, configSourceEtcd:
+		// CONFIG_REMOTE_ENDPOINT/CONFIG_REMOTE_PATH point at the Consul
+		// agent or etcd cluster and the key the config document is stored
+		// under, e.g. endpoint "http://127.0.0.1:8500", path
+		// "pd-ai-agents-registry/config".
+		endpoint := os.Getenv("CONFIG_REMOTE_ENDPOINT")
+		path := os.Getenv("CONFIG_REMOTE_PATH")
+		if endpoint == "" || path == "" {
+			return nil, fmt.Errorf("CONFIG_REMOTE_ENDPOINT and CONFIG_REMOTE_PATH are required when CONFIG_SOURCE=%s", source)
+		}
+		v.SetConfigType("json")
+		if err := v.AddRemoteProvider(source, endpoint, path); err != nil {
+			return nil, fmt.Errorf("error configuring %s remote provider: %w", source, err)
+		}
+		if err := v.ReadRemoteConfig(); err != nil {
+			return nil, fmt.Errorf("error reading %s config: %w", source, err)
+		}
+	case configSourceVault, configSourceAWSSM:
+		// The entire config document (not just individual secret fields)
+		// lives as a single JSON blob in the secret store; path comes from
+		// CONFIG_REMOTE_PATH, e.g. "secret/data/pd-ai-agents-registry#config"
+		// for Vault or an ARN#jsonkey pair for Secrets Manager.
+		path := os.Getenv("CONFIG_REMOTE_PATH")
+		if path == "" {
+			return nil, fmt.Errorf("CONFIG_REMOTE_PATH is required when CONFIG_SOURCE=%s", source)
+		}
+		resolver, err := newSecretResolver(source)
+		if err != nil {
+			return nil, err
+		}
+		data, err := resolver.Resolve(source + "://" + path)
+		if err != nil {
+			return nil, fmt.Errorf("error reading config from %s: %w", source, err)
+		}
+		v.SetConfigType("json")
+		if err := v.ReadConfig(strings.NewReader(data)); err != nil {
+			return nil, fmt.Errorf("error parsing config from %s: %w", source, err)
+		}
+	default:
+		v.SetConfigName(".env")
+		v.SetConfigType("env")
+		v.AddConfigPath(".")
+		v.AddConfigPath("./config")
+		v.AddConfigPath("../")
+
+		if err := v.ReadInConfig(); err != nil {
+			if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
+				return nil, fmt.Errorf("error reading config file: %w", err)
+			}
+			log.Printf("No config file found, using environment variables")
 		}
-		log.Printf("No config file found, using environment variables")
 	}
 
 	// Explicitly bind environment variables with underscores
@@ -111,6 +344,33 @@ func Load() (*Config, error) {
 	if err := v.BindEnv("s3.region", "S3__REGION"); err != nil {
 		return nil, fmt.Errorf("error binding environment variable: %w", err)
 	}
+	if err := v.BindEnv("storage.type", "STORAGE__TYPE"); err != nil {
+		return nil, fmt.Errorf("error binding environment variable: %w", err)
+	}
+	if err := v.BindEnv("storage.filesystem.base_dir", "STORAGE__FILESYSTEM__BASE_DIR"); err != nil {
+		return nil, fmt.Errorf("error binding environment variable: %w", err)
+	}
+	if err := v.BindEnv("storage.encryption.mode", "STORAGE__ENCRYPTION__MODE"); err != nil {
+		return nil, fmt.Errorf("error binding environment variable: %w", err)
+	}
+	if err := v.BindEnv("storage.encryption.kms_provider", "STORAGE__ENCRYPTION__KMS_PROVIDER"); err != nil {
+		return nil, fmt.Errorf("error binding environment variable: %w", err)
+	}
+	if err := v.BindEnv("storage.encryption.kms_key_id", "STORAGE__ENCRYPTION__KMS_KEY_ID"); err != nil {
+		return nil, fmt.Errorf("error binding environment variable: %w", err)
+	}
+	if err := v.BindEnv("storage.encryption.local_master_key", "STORAGE__ENCRYPTION__LOCAL_MASTER_KEY"); err != nil {
+		return nil, fmt.Errorf("error binding environment variable: %w", err)
+	}
+	if err := v.BindEnv("redis.addr", "REDIS__ADDR"); err != nil {
+		return nil, fmt.Errorf("error binding environment variable: %w", err)
+	}
+	if err := v.BindEnv("redis.password", "REDIS__PASSWORD"); err != nil {
+		return nil, fmt.Errorf("error binding environment variable: %w", err)
+	}
+	if err := v.BindEnv("redis.db", "REDIS__DB"); err != nil {
+		return nil, fmt.Errorf("error binding environment variable: %w", err)
+	}
 	if err := v.BindEnv("mongodb.uri", "MONGODB__URI"); err != nil {
 		return nil, fmt.Errorf("error binding environment variable: %w", err)
 	}
@@ -123,9 +383,63 @@ func Load() (*Config, error) {
 	if err := v.BindEnv("jwt.expiry_hour", "JWT__EXPIRY_HOUR"); err != nil {
 		return nil, fmt.Errorf("error binding environment variable: %w", err)
 	}
+	if err := v.BindEnv("jwt.refresh_expiry_hour", "JWT__REFRESH_EXPIRY_HOUR"); err != nil {
+		return nil, fmt.Errorf("error binding environment variable: %w", err)
+	}
+	if err := v.BindEnv("jwt.algorithm", "JWT__ALGORITHM"); err != nil {
+		return nil, fmt.Errorf("error binding environment variable: %w", err)
+	}
+	if err := v.BindEnv("jwt.private_key_path", "JWT__PRIVATE_KEY_PATH"); err != nil {
+		return nil, fmt.Errorf("error binding environment variable: %w", err)
+	}
+	if err := v.BindEnv("jwt.private_key_pem", "JWT__PRIVATE_KEY_PEM"); err != nil {
+		return nil, fmt.Errorf("error binding environment variable: %w", err)
+	}
+	if err := v.BindEnv("jwt.key_id", "JWT__KEY_ID"); err != nil {
+		return nil, fmt.Errorf("error binding environment variable: %w", err)
+	}
 	if err := v.BindEnv("admin.password", "ADMIN__PASSWORD"); err != nil {
 		return nil, fmt.Errorf("error binding environment variable: %w", err)
 	}
+	if err := v.BindEnv("cleanup.enabled", "CLEANUP__ENABLED"); err != nil {
+		return nil, fmt.Errorf("error binding environment variable: %w", err)
+	}
+	if err := v.BindEnv("cleanup.interval_hour", "CLEANUP__INTERVAL_HOUR"); err != nil {
+		return nil, fmt.Errorf("error binding environment variable: %w", err)
+	}
+	if err := v.BindEnv("cleanup.keep_last_n", "CLEANUP__KEEP_LAST_N"); err != nil {
+		return nil, fmt.Errorf("error binding environment variable: %w", err)
+	}
+	if err := v.BindEnv("cleanup.max_age_days", "CLEANUP__MAX_AGE_DAYS"); err != nil {
+		return nil, fmt.Errorf("error binding environment variable: %w", err)
+	}
+	if err := v.BindEnv("uploads.session_ttl_hour", "UPLOADS__SESSION_TTL_HOUR"); err != nil {
+		return nil, fmt.Errorf("error binding environment variable: %w", err)
+	}
+	if err := v.BindEnv("registry.apk_signing_key_pem", "REGISTRY__APK_SIGNING_KEY_PEM"); err != nil {
+		return nil, fmt.Errorf("error binding environment variable: %w", err)
+	}
+	if err := v.BindEnv("registry.apk_key_name", "REGISTRY__APK_KEY_NAME"); err != nil {
+		return nil, fmt.Errorf("error binding environment variable: %w", err)
+	}
+	if err := v.BindEnv("cache.package_size", "CACHE__PACKAGE_SIZE"); err != nil {
+		return nil, fmt.Errorf("error binding environment variable: %w", err)
+	}
+	if err := v.BindEnv("cache.package_ttl_seconds", "CACHE__PACKAGE_TTL_SECONDS"); err != nil {
+		return nil, fmt.Errorf("error binding environment variable: %w", err)
+	}
+	if err := v.BindEnv("cache.version_size", "CACHE__VERSION_SIZE"); err != nil {
+		return nil, fmt.Errorf("error binding environment variable: %w", err)
+	}
+	if err := v.BindEnv("cache.version_ttl_seconds", "CACHE__VERSION_TTL_SECONDS"); err != nil {
+		return nil, fmt.Errorf("error binding environment variable: %w", err)
+	}
+	if err := v.BindEnv("cache.negative_ttl_seconds", "CACHE__NEGATIVE_TTL_SECONDS"); err != nil {
+		return nil, fmt.Errorf("error binding environment variable: %w", err)
+	}
+	if err := v.BindEnv("versions.purge_grace_hours", "VERSIONS__PURGE_GRACE_HOURS"); err != nil {
+		return nil, fmt.Errorf("error binding environment variable: %w", err)
+	}
 
 	// Create config struct
 	config := &Config{}
@@ -141,6 +455,14 @@ func Load() (*Config, error) {
 		return nil, fmt.Errorf("error unmarshaling config: %w", err)
 	}
 
+	// Secret-bearing fields may hold a vault://path#field or
+	// awssm://arn#jsonkey reference instead of a literal value; resolve
+	// those against the real secret store now so the rest of Load (and
+	// every caller downstream) only ever sees plaintext.
+	if err := resolveSecretFields(config); err != nil {
+		return nil, fmt.Errorf("error resolving secrets: %w", err)
+	}
+
 	// Debug print the config struct after unmarshaling
 	log.Printf("Config struct after unmarshaling:")
 	log.Printf("port=%s, S3 Config: Region=%s, Bucket=%s, AccessKeyID=%s, SecretAccessKey=%s, Endpoint=%s",
@@ -156,22 +478,96 @@ func Load() (*Config, error) {
 		config.Server.Host = "localhost"
 	}
 
-	// Validate required fields
-	if config.S3.Region == "" {
-		return nil, fmt.Errorf("S3__REGION is required")
+	if config.Storage.Type == "" {
+		config.Storage.Type = "s3"
+	}
+
+	if config.Storage.Encryption.Mode == "" {
+		config.Storage.Encryption.Mode = "none"
+	}
+
+	if config.JWT.Algorithm == "" {
+		config.JWT.Algorithm = "HS256"
+	}
+	if config.Registry.APKKeyName == "" {
+		config.Registry.APKKeyName = "registry@pd-ai-agents.local"
+	}
+	if config.Cache.PackageSize == 0 {
+		config.Cache.PackageSize = 1000
+	}
+	if config.Cache.PackageTTLSeconds == 0 {
+		config.Cache.PackageTTLSeconds = 30
+	}
+	if config.Cache.VersionSize == 0 {
+		config.Cache.VersionSize = 2000
 	}
-	if config.S3.AccessKeyID == "" {
-		return nil, fmt.Errorf("S3__ACCESS_KEY_ID is required")
+	if config.Cache.VersionTTLSeconds == 0 {
+		config.Cache.VersionTTLSeconds = 30
 	}
-	if config.S3.SecretAccessKey == "" {
-		return nil, fmt.Errorf("S3__SECRET_ACCESS_KEY is required")
+	if config.Cache.NegativeTTLSeconds == 0 {
+		config.Cache.NegativeTTLSeconds = 5
 	}
-	if config.S3.Bucket == "" {
-		return nil, fmt.Errorf("S3__BUCKET is required")
+	if config.Versions.PurgeGraceHours == 0 {
+		config.Versions.PurgeGraceHours = 72
+	}
+	if config.JWT.RefreshExpiryHour == 0 {
+		config.JWT.RefreshExpiryHour = 24 * 30
+	}
+	// The storage backend config was historically just the top-level S3
+	// block; keep that working unless storage.s3.* was set explicitly.
+	if config.Storage.S3 == (S3Config{}) {
+		config.Storage.S3 = config.S3
+	}
+
+	// Validate required fields. Only the block for the active storage driver
+	// is checked, so e.g. a filesystem deployment never needs S3 credentials.
+	switch config.Storage.Type {
+	case "s3":
+		if config.Storage.S3.Region == "" {
+			return nil, fmt.Errorf("S3__REGION is required")
+		}
+		if config.Storage.S3.AccessKeyID == "" {
+			return nil, fmt.Errorf("S3__ACCESS_KEY_ID is required")
+		}
+		if config.Storage.S3.SecretAccessKey == "" {
+			return nil, fmt.Errorf("S3__SECRET_ACCESS_KEY is required")
+		}
+		if config.Storage.S3.Bucket == "" {
+			return nil, fmt.Errorf("S3__BUCKET is required")
+		}
+	case "minio":
+		if config.Storage.MinIO.Endpoint == "" {
+			return nil, fmt.Errorf("STORAGE__MINIO__ENDPOINT is required")
+		}
+		if config.Storage.MinIO.Bucket == "" {
+			return nil, fmt.Errorf("STORAGE__MINIO__BUCKET is required")
+		}
+	case "gcs":
+		if config.Storage.GCS.Bucket == "" {
+			return nil, fmt.Errorf("STORAGE__GCS__BUCKET is required")
+		}
+	case "azure":
+		if config.Storage.Azure.AccountName == "" {
+			return nil, fmt.Errorf("STORAGE__AZURE__ACCOUNT_NAME is required")
+		}
+		if config.Storage.Azure.Container == "" {
+			return nil, fmt.Errorf("STORAGE__AZURE__CONTAINER is required")
+		}
+	case "filesystem":
+		// No required fields; BaseDir defaults in NewFilesystemBackend.
 	}
 	if config.Admin.Password == "" {
 		return nil, fmt.Errorf("ADMIN__PASSWORD is required")
 	}
+	if config.Redis.Addr == "" {
+		config.Redis.Addr = "localhost:6379"
+	}
+	if config.Cleanup.IntervalHour == 0 {
+		config.Cleanup.IntervalHour = 24
+	}
+	if config.Uploads.SessionTTLHour == 0 {
+		config.Uploads.SessionTTLHour = 24
+	}
 
 	// Print final config
 	log.Printf("Loaded configuration: AppEnv=%s, Region=%s, Endpoint=%s, Bucket=%s",
@@ -181,5 +577,6 @@ func Load() (*Config, error) {
 		config.S3.Bucket,
 	)
 
+	activeViper = v
 	return config, nil
 }
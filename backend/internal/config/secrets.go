@@ -0,0 +1,228 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	vaultapi "github.com/hashicorp/vault/api"
+)
+
+// SecretResolver fetches the plaintext value referenced by a URI such as
+// vault://secret/data/registry#s3_secret_key or
+// awssm://arn:aws:secretsmanager:...#jsonkey. Implementations own whatever
+// client/connection the backing store needs.
+type SecretResolver interface {
+	Resolve(uri string) (string, error)
+}
+
+// secretFieldPrefixes are the URI schemes resolveSecretFields recognizes;
+// any other value is left untouched as a literal.
+var secretFieldPrefixes = []string{"vault://", "awssm://"}
+
+// isSecretRef reports whether value is a vault:// or awssm:// reference
+// rather than a literal secret.
+func isSecretRef(value string) bool {
+	for _, prefix := range secretFieldPrefixes {
+		if strings.HasPrefix(value, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveSecretFields replaces every vault:// or awssm:// reference held by
+// the config's secret-bearing fields with the plaintext value it points at.
+// Resolvers are only constructed if at least one field actually needs them,
+// so a deployment that never references Vault doesn't need a
+// VAULT_ADDR/VAULT_TOKEN to be set.
+func resolveSecretFields(cfg *Config) error {
+	var vault, awssm SecretResolver
+
+	resolve := func(value string) (string, error) {
+		switch {
+		case strings.HasPrefix(value, "vault://"):
+			if vault == nil {
+				r, err := newSecretResolver(configSourceVault)
+				if err != nil {
+					return "", err
+				}
+				vault = r
+			}
+			return vault.Resolve(value)
+		case strings.HasPrefix(value, "awssm://"):
+			if awssm == nil {
+				r, err := newSecretResolver(configSourceAWSSM)
+				if err != nil {
+					return "", err
+				}
+				awssm = r
+			}
+			return awssm.Resolve(value)
+		default:
+			return value, nil
+		}
+	}
+
+	fields := []*string{
+		&cfg.S3.SecretAccessKey,
+		&cfg.Storage.S3.SecretAccessKey,
+		&cfg.JWT.Secret,
+		&cfg.JWT.PrivateKeyPEM,
+		&cfg.Storage.Encryption.LocalMasterKey,
+		&cfg.Admin.Password,
+		&cfg.MongoDB.URI,
+		&cfg.Registry.APKSigningKeyPEM,
+	}
+	for _, field := range fields {
+		if !isSecretRef(*field) {
+			continue
+		}
+		resolved, err := resolve(*field)
+		if err != nil {
+			return fmt.Errorf("failed to resolve secret %q: %w", *field, err)
+		}
+		*field = resolved
+	}
+
+	return nil
+}
+
+// newSecretResolver builds the SecretResolver for the given CONFIG_SOURCE
+// (or the scheme of a vault://.../awssm://... reference, which shares the
+// same names).
+func newSecretResolver(source string) (SecretResolver, error) {
+	switch source {
+	case configSourceVault:
+		return newVaultResolver()
+	case configSourceAWSSM:
+		return newAWSSecretsManagerResolver()
+	default:
+		return nil, fmt.Errorf("config: no secret resolver for source %q", source)
+	}
+}
+
+// VaultResolver resolves vault://path#field references against a HashiCorp
+// Vault KV store.
+type VaultResolver struct {
+	client *vaultapi.Client
+}
+
+func newVaultResolver() (*VaultResolver, error) {
+	vc := vaultapi.DefaultConfig()
+	if addr := os.Getenv("VAULT_ADDR"); addr != "" {
+		vc.Address = addr
+	}
+	client, err := vaultapi.NewClient(vc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build vault client: %w", err)
+	}
+	if token := os.Getenv("VAULT_TOKEN"); token != "" {
+		client.SetToken(token)
+	}
+	return &VaultResolver{client: client}, nil
+}
+
+// Resolve reads uri in the form vault://<path>#<field> and returns the
+// string value of that field in the secret at <path>.
+func (r *VaultResolver) Resolve(uri string) (string, error) {
+	path, field, err := splitSecretURI(uri, "vault://")
+	if err != nil {
+		return "", err
+	}
+
+	secret, err := r.client.Logical().Read(path)
+	if err != nil {
+		return "", fmt.Errorf("vault: failed to read %s: %w", path, err)
+	}
+	if secret == nil || secret.Data == nil {
+		return "", fmt.Errorf("vault: no secret found at %s", path)
+	}
+
+	// KV v2 nests the actual fields under a "data" key; fall back to the
+	// top-level map for KV v1 mounts.
+	data := secret.Data
+	if nested, ok := secret.Data["data"].(map[string]interface{}); ok {
+		data = nested
+	}
+
+	value, ok := data[field]
+	if !ok {
+		return "", fmt.Errorf("vault: secret at %s has no field %q", path, field)
+	}
+	str, ok := value.(string)
+	if !ok {
+		return "", fmt.Errorf("vault: field %q at %s is not a string", field, path)
+	}
+	return str, nil
+}
+
+// AWSSecretsManagerResolver resolves awssm://arn#jsonkey references against
+// AWS Secrets Manager, treating the secret's value as a JSON object and
+// picking jsonkey out of it.
+type AWSSecretsManagerResolver struct {
+	client *secretsmanager.Client
+}
+
+func newAWSSecretsManagerResolver() (*AWSSecretsManagerResolver, error) {
+	awsCfg, err := awsconfig.LoadDefaultConfig(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS config: %w", err)
+	}
+	return &AWSSecretsManagerResolver{client: secretsmanager.NewFromConfig(awsCfg)}, nil
+}
+
+// Resolve reads uri in the form awssm://<arn>#<jsonkey> and returns the
+// string value of jsonkey within the secret's JSON payload.
+func (r *AWSSecretsManagerResolver) Resolve(uri string) (string, error) {
+	arn, key, err := splitSecretURI(uri, "awssm://")
+	if err != nil {
+		return "", err
+	}
+
+	out, err := r.client.GetSecretValue(context.Background(), &secretsmanager.GetSecretValueInput{
+		SecretId: &arn,
+	})
+	if err != nil {
+		return "", fmt.Errorf("awssm: failed to fetch %s: %w", arn, err)
+	}
+	if out.SecretString == nil {
+		return "", fmt.Errorf("awssm: secret %s has no string payload", arn)
+	}
+
+	values, err := parseJSONSecret(*out.SecretString)
+	if err != nil {
+		return "", fmt.Errorf("awssm: secret %s is not a JSON object: %w", arn, err)
+	}
+	value, ok := values[key]
+	if !ok {
+		return "", fmt.Errorf("awssm: secret %s has no key %q", arn, key)
+	}
+	return value, nil
+}
+
+// parseJSONSecret unmarshals a Secrets Manager payload into a flat string
+// map; agent package secrets are never nested, so anything more complex is
+// treated as a format error.
+func parseJSONSecret(raw string) (map[string]string, error) {
+	var values map[string]string
+	if err := json.Unmarshal([]byte(raw), &values); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// splitSecretURI splits a "<scheme><path>#<field>" reference into its path
+// and field parts.
+func splitSecretURI(uri, scheme string) (path, field string, err error) {
+	rest := strings.TrimPrefix(uri, scheme)
+	parts := strings.SplitN(rest, "#", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", fmt.Errorf("config: %q is not a valid %s reference, expected %s<path>#<field>", uri, scheme, scheme)
+	}
+	return parts[0], parts[1], nil
+}
@@ -0,0 +1,42 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// Watch re-reads the config file whenever it changes on disk and invokes
+// onChange with the freshly unmarshaled (and secret-resolved) Config. It
+// only has an effect after a file-backed Load(); remote/secret-backed
+// sources have no local file to watch and Watch becomes a no-op until a
+// future poll-based provider is added.
+//
+// onChange is called from viper's fsnotify goroutine; callers that hand the
+// new config to shared state (e.g. Handler.SetConfig) must do so safely for
+// concurrent reads, which is exactly what Handler's atomic pointer is for.
+func Watch(ctx context.Context, onChange func(*Config)) error {
+	v := activeViper
+	if v == nil {
+		return fmt.Errorf("config: Watch called before Load")
+	}
+
+	v.OnConfigChange(func(e fsnotify.Event) {
+		cfg := &Config{}
+		if err := v.Unmarshal(cfg); err != nil {
+			log.Printf("config: failed to reload after change to %s: %v", e.Name, err)
+			return
+		}
+		if err := resolveSecretFields(cfg); err != nil {
+			log.Printf("config: failed to resolve secrets after change to %s: %v", e.Name, err)
+			return
+		}
+		log.Printf("config: reloaded after change to %s", e.Name)
+		onChange(cfg)
+	})
+	v.WatchConfig()
+
+	return nil
+}
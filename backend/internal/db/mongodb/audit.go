@@ -0,0 +1,85 @@
+package mongodb
+
+import (
+	"context"
+	"time"
+
+	"github.com/Parallels/pd-ai-agents-registry/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const auditLogCollection = "audit_log"
+
+// LogAudit appends entry to the append-only audit log, stamping CreatedAt
+// itself. There is no UpdateAudit or DeleteAudit.
+func (c *Client) LogAudit(ctx context.Context, entry models.AuditEntry) error {
+	collection := c.database.Collection(auditLogCollection)
+
+	entry.CreatedAt = time.Now()
+
+	_, err := collection.InsertOne(ctx, entry)
+	return err
+}
+
+// AuditQueryFilter narrows QueryAudit's results. The zero value returns the
+// first page of every entry, newest first.
+type AuditQueryFilter struct {
+	Actor   string
+	Action  string
+	Target  string
+	Page    int
+	PerPage int
+}
+
+// QueryAudit retrieves a filtered, newest-first page of audit log entries
+// along with the total number of entries matching the filter.
+func (c *Client) QueryAudit(ctx context.Context, filter AuditQueryFilter) ([]models.AuditEntry, int64, error) {
+	collection := c.database.Collection(auditLogCollection)
+
+	query := bson.M{}
+	if filter.Actor != "" {
+		query["actor"] = filter.Actor
+	}
+	if filter.Action != "" {
+		query["action"] = filter.Action
+	}
+	if filter.Target != "" {
+		query["target"] = filter.Target
+	}
+
+	page := filter.Page
+	if page < 1 {
+		page = defaultPage
+	}
+	perPage := filter.PerPage
+	if perPage < 1 {
+		perPage = defaultPerPage
+	}
+	if perPage > maxPerPage {
+		perPage = maxPerPage
+	}
+
+	total, err := collection.CountDocuments(ctx, query)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}}).
+		SetSkip(int64((page - 1) * perPage)).
+		SetLimit(int64(perPage))
+
+	cursor, err := collection.Find(ctx, query, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var entries []models.AuditEntry
+	if err := cursor.All(ctx, &entries); err != nil {
+		return nil, 0, err
+	}
+
+	return entries, total, nil
+}
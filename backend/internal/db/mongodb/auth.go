@@ -0,0 +1,141 @@
+package mongodb
+
+import (
+	"context"
+	"time"
+
+	"github.com/Parallels/pd-ai-agents-registry/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	refreshTokensCollection = "refresh_tokens"
+	revokedTokensCollection = "revoked_tokens"
+)
+
+// revokedToken is the document stored per denylisted access token JTI.
+// ExpiresAt mirrors the token's own expiry so a TTL index can reap entries
+// once the token they'd block would have expired anyway.
+type revokedToken struct {
+	JTI       string    `bson:"jti"`
+	ExpiresAt time.Time `bson:"expires_at"`
+}
+
+func (c *Client) GetUserByID(ctx context.Context, id primitive.ObjectID) (*models.User, error) {
+	collection := c.database.Collection(usersCollection)
+
+	var user models.User
+	err := collection.FindOne(ctx, bson.M{"_id": id}).Decode(&user)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+// UpdateUserPassword replaces a user's stored password hash and algorithm,
+// used both for the transparent bcrypt-to-argon2id upgrade on login and
+// for any future explicit password change.
+func (c *Client) UpdateUserPassword(ctx context.Context, userID primitive.ObjectID, hash, algorithm string) error {
+	collection := c.database.Collection(usersCollection)
+
+	_, err := collection.UpdateOne(ctx, bson.M{"_id": userID}, bson.M{
+		"$set": bson.M{
+			"password":           hash,
+			"password_algorithm": algorithm,
+			"updated_at":         time.Now(),
+		},
+	})
+	return err
+}
+
+// CreateRefreshToken persists a newly issued refresh token, identified only
+// by its sha256 hash.
+func (c *Client) CreateRefreshToken(ctx context.Context, token *models.RefreshToken) error {
+	collection := c.database.Collection(refreshTokensCollection)
+
+	if token.ID.IsZero() {
+		token.ID = primitive.NewObjectID()
+	}
+	token.CreatedAt = time.Now()
+
+	_, err := collection.InsertOne(ctx, token)
+	return err
+}
+
+// GetRefreshToken looks up a refresh token by its sha256 hash. It returns
+// nil, nil if no token matches tokenHash.
+func (c *Client) GetRefreshToken(ctx context.Context, tokenHash string) (*models.RefreshToken, error) {
+	collection := c.database.Collection(refreshTokensCollection)
+
+	var token models.RefreshToken
+	err := collection.FindOne(ctx, bson.M{"token_hash": tokenHash}).Decode(&token)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &token, nil
+}
+
+// RevokeRefreshToken marks a single refresh token (identified by its hash)
+// as revoked, e.g. on logout or after it's been rotated.
+func (c *Client) RevokeRefreshToken(ctx context.Context, tokenHash string) error {
+	collection := c.database.Collection(refreshTokensCollection)
+
+	now := time.Now()
+	_, err := collection.UpdateOne(ctx,
+		bson.M{"token_hash": tokenHash},
+		bson.M{"$set": bson.M{"revoked_at": now}},
+	)
+	return err
+}
+
+// RevokeAllRefreshTokens revokes every non-revoked refresh token belonging
+// to userID, used by logout-all to sign the user out of every device.
+func (c *Client) RevokeAllRefreshTokens(ctx context.Context, userID primitive.ObjectID) error {
+	collection := c.database.Collection(refreshTokensCollection)
+
+	now := time.Now()
+	_, err := collection.UpdateMany(ctx,
+		bson.M{"user_id": userID, "revoked_at": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"revoked_at": now}},
+	)
+	return err
+}
+
+// DenylistJTI records an access token's jti as revoked before its natural
+// expiry. expiresAt should be the token's own "exp" claim so the TTL index
+// on revoked_tokens can clean up the entry once it can no longer matter.
+func (c *Client) DenylistJTI(ctx context.Context, jti string, expiresAt time.Time) error {
+	collection := c.database.Collection(revokedTokensCollection)
+
+	_, err := collection.UpdateOne(ctx,
+		bson.M{"jti": jti},
+		bson.M{"$setOnInsert": revokedToken{JTI: jti, ExpiresAt: expiresAt}},
+		options.Update().SetUpsert(true),
+	)
+	return err
+}
+
+// IsJTIDenylisted reports whether an access token's jti has been revoked.
+func (c *Client) IsJTIDenylisted(ctx context.Context, jti string) (bool, error) {
+	collection := c.database.Collection(revokedTokensCollection)
+
+	err := collection.FindOne(ctx, bson.M{"jti": jti}).Err()
+	if err == nil {
+		return true, nil
+	}
+	if err == mongo.ErrNoDocuments {
+		return false, nil
+	}
+	return false, err
+}
@@ -0,0 +1,148 @@
+package mongodb
+
+import (
+	"context"
+	"time"
+
+	"github.com/Parallels/pd-ai-agents-registry/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const blobUploadsCollection = "blob_uploads"
+
+// StartBlobUpload creates a new resumable upload session tracking document
+// with the given id, expiring at expiresAt unless it's finished or canceled
+// first.
+func (c *Client) StartBlobUpload(ctx context.Context, sessionID string, packageID primitive.ObjectID, version, filename, contentType string, sha256State []byte, expiresAt time.Time) (*models.BlobUploadSession, error) {
+	collection := c.database.Collection(blobUploadsCollection)
+
+	session := &models.BlobUploadSession{
+		ID:          sessionID,
+		PackageID:   packageID,
+		Version:     version,
+		Filename:    filename,
+		ContentType: contentType,
+		Sha256State: sha256State,
+		CreatedAt:   time.Now(),
+		ExpiresAt:   expiresAt,
+	}
+
+	if _, err := collection.InsertOne(ctx, session); err != nil {
+		return nil, err
+	}
+
+	return session, nil
+}
+
+// GetBlobUpload retrieves an upload session by id. It returns (nil, nil) if
+// the session doesn't exist (never started, already finished, canceled, or
+// swept after expiring), matching GetPackage/GetVersion's not-found
+// convention.
+func (c *Client) GetBlobUpload(ctx context.Context, sessionID string) (*models.BlobUploadSession, error) {
+	collection := c.database.Collection(blobUploadsCollection)
+
+	var session models.BlobUploadSession
+	err := collection.FindOne(ctx, bson.M{"_id": sessionID}).Decode(&session)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &session, nil
+}
+
+// AppendBlobChunk records one PATCH request's worth of content-defined
+// chunks against an upload session: the chunk hashes are appended in order,
+// received_bytes is advanced by addedBytes, and sha256State is replaced with
+// the running hash's state after those bytes, so the next append (or
+// Finish) never needs to re-read bytes already received. It returns the
+// session's state after the update.
+func (c *Client) AppendBlobChunk(ctx context.Context, sessionID string, hashes []string, addedBytes int64, sha256State []byte) (*models.BlobUploadSession, error) {
+	collection := c.database.Collection(blobUploadsCollection)
+
+	var session models.BlobUploadSession
+	err := collection.FindOneAndUpdate(
+		ctx,
+		bson.M{"_id": sessionID},
+		bson.M{
+			"$push": bson.M{"chunk_hashes": bson.M{"$each": hashes}},
+			"$inc":  bson.M{"received_bytes": addedBytes},
+			"$set":  bson.M{"sha256_state": sha256State},
+		},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&session)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &session, nil
+}
+
+// FinishBlobUpload atomically fetches and removes an upload session's
+// tracking document, returning its final state so the caller can reassemble
+// and verify the upload without a second round trip. It returns (nil, nil)
+// once the session no longer exists.
+func (c *Client) FinishBlobUpload(ctx context.Context, sessionID string) (*models.BlobUploadSession, error) {
+	collection := c.database.Collection(blobUploadsCollection)
+
+	var session models.BlobUploadSession
+	err := collection.FindOneAndDelete(ctx, bson.M{"_id": sessionID}).Decode(&session)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &session, nil
+}
+
+// CancelBlobUpload discards an upload session's tracking document. It's a
+// no-op if the session has already been finished, canceled, or swept.
+func (c *Client) CancelBlobUpload(ctx context.Context, sessionID string) error {
+	collection := c.database.Collection(blobUploadsCollection)
+
+	_, err := collection.DeleteOne(ctx, bson.M{"_id": sessionID})
+	return err
+}
+
+// ListExpiredBlobUploads returns every upload session whose expiry is
+// before cutoff, for the sweeper to cancel.
+func (c *Client) ListExpiredBlobUploads(ctx context.Context, cutoff time.Time) ([]models.BlobUploadSession, error) {
+	collection := c.database.Collection(blobUploadsCollection)
+
+	cursor, err := collection.Find(ctx, bson.M{"expires_at": bson.M{"$lt": cutoff}})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var sessions []models.BlobUploadSession
+	if err := cursor.All(ctx, &sessions); err != nil {
+		return nil, err
+	}
+
+	return sessions, nil
+}
+
+// ChunkRetained reports whether hash is currently referenced by at least one
+// file (ref_count > 0), so the sweeper can tell an orphaned chunk written by
+// an abandoned upload apart from one another upload has since retained.
+func (c *Client) ChunkRetained(ctx context.Context, hash string) (bool, error) {
+	collection := c.database.Collection(chunksCollection)
+
+	count, err := collection.CountDocuments(ctx, bson.M{"_id": hash, "ref_count": bson.M{"$gt": 0}})
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
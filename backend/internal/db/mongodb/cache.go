@@ -0,0 +1,361 @@
+package mongodb
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Parallels/pd-ai-agents-registry/internal/config"
+	"github.com/Parallels/pd-ai-agents-registry/internal/models"
+	"github.com/Parallels/pd-ai-agents-registry/internal/semver"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// cacheOpsTotal counts every lru hit, miss, and eviction, labeled by which
+// cache ("package" or "version") it happened in, so a deployment can watch
+// cache effectiveness on its existing Prometheus/Grafana stack.
+var cacheOpsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "registry_metadata_cache_operations_total",
+	Help: "In-process metadata cache operations, by cache name and result (hit, miss, eviction).",
+}, []string{"cache", "result"})
+
+// negativeValue marks a cached "not found" result, so a subsequent get can
+// tell "this package/version definitely doesn't exist" apart from "this
+// key just isn't cached yet".
+type negativeValue struct{}
+
+// lruItem is the value stored in lru.order; entry.expiresAt makes a TTL
+// miss indistinguishable from an unset key once it's passed.
+type lruItem struct {
+	key       string
+	value     any
+	expiresAt time.Time
+}
+
+// lru is a fixed-size, per-entry-TTL cache: a doubly linked list gives O(1)
+// least-recently-used eviction, and a map alongside it gives O(1) lookup.
+// Safe for concurrent use.
+type lru struct {
+	name string
+
+	mu    sync.Mutex
+	size  int
+	items map[string]*list.Element
+	order *list.List
+}
+
+func newLRU(name string, size int) *lru {
+	return &lru{name: name, size: size, items: make(map[string]*list.Element), order: list.New()}
+}
+
+func (c *lru) get(key string) (any, bool) {
+	if c.size <= 0 {
+		return nil, false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.items[key]
+	if !ok {
+		cacheOpsTotal.WithLabelValues(c.name, "miss").Inc()
+		return nil, false
+	}
+
+	item := el.Value.(*lruItem)
+	if time.Now().After(item.expiresAt) {
+		c.order.Remove(el)
+		delete(c.items, key)
+		cacheOpsTotal.WithLabelValues(c.name, "miss").Inc()
+		return nil, false
+	}
+
+	c.order.MoveToFront(el)
+	cacheOpsTotal.WithLabelValues(c.name, "hit").Inc()
+	return item.value, true
+}
+
+func (c *lru) set(key string, value any, ttl time.Duration) {
+	if c.size <= 0 || ttl <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.items[key]; ok {
+		el.Value.(*lruItem).value = value
+		el.Value.(*lruItem).expiresAt = time.Now().Add(ttl)
+		c.order.MoveToFront(el)
+		return
+	}
+
+	el := c.order.PushFront(&lruItem{key: key, value: value, expiresAt: time.Now().Add(ttl)})
+	c.items[key] = el
+
+	if c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*lruItem).key)
+		cacheOpsTotal.WithLabelValues(c.name, "eviction").Inc()
+	}
+}
+
+// deletePrefix drops every cached key starting with prefix, used to
+// invalidate every cached lookup for one package (both its own GetPackage
+// entry and, in the version cache, every version/list entry keyed under
+// it) in a single call.
+func (c *lru) deletePrefix(prefix string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key, el := range c.items {
+		if strings.HasPrefix(key, prefix) {
+			c.order.Remove(el)
+			delete(c.items, key)
+		}
+	}
+}
+
+// CachingClient decorates Client with an in-process, size-bounded LRU in
+// front of GetPackage, GetVersion, and ListVersions -- the lookups a
+// package manager repeats on every install -- so a hot package's metadata
+// doesn't round-trip to MongoDB on every request. The mutations overridden
+// below invalidate what they change immediately rather than waiting out the
+// TTL; writes through methods not overridden here (such as
+// SetVersionProcessingState, ApplyManifest, SetFileSignature, or
+// DeleteVersionsBulk) or from another process show up only once the
+// affected entry's TTL expires. Methods not overridden here fall through to
+// the embedded *Client unchanged.
+type CachingClient struct {
+	*Client
+
+	packages    *lru
+	versions    *lru
+	negativeTTL time.Duration
+	packageTTL  time.Duration
+	versionTTL  time.Duration
+}
+
+// NewCachingClient wraps client with the caches described by cfg. A zero
+// Size in cfg effectively disables that cache (every get misses, nothing
+// is ever retained).
+func NewCachingClient(client *Client, cfg config.CacheConfig) *CachingClient {
+	return &CachingClient{
+		Client:      client,
+		packages:    newLRU("package", cfg.PackageSize),
+		versions:    newLRU("version", cfg.VersionSize),
+		negativeTTL: time.Duration(cfg.NegativeTTLSeconds) * time.Second,
+		packageTTL:  time.Duration(cfg.PackageTTLSeconds) * time.Second,
+		versionTTL:  time.Duration(cfg.VersionTTLSeconds) * time.Second,
+	}
+}
+
+func (c *CachingClient) GetPackage(ctx context.Context, name string) (*models.Package, error) {
+	key := "name:" + name
+	if cached, ok := c.packages.get(key); ok {
+		if _, negative := cached.(negativeValue); negative {
+			return nil, nil
+		}
+		return cached.(*models.Package), nil
+	}
+
+	pkg, err := c.Client.GetPackage(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if pkg == nil {
+		c.packages.set(key, negativeValue{}, c.negativeTTL)
+		return nil, nil
+	}
+
+	c.packages.set(key, pkg, c.packageTTL)
+	return pkg, nil
+}
+
+func (c *CachingClient) GetVersion(ctx context.Context, packageID primitive.ObjectID, version string) (*models.Version, error) {
+	key := versionPrefix(packageID) + "v:" + version
+	if cached, ok := c.versions.get(key); ok {
+		if _, negative := cached.(negativeValue); negative {
+			return nil, nil
+		}
+		return cached.(*models.Version), nil
+	}
+
+	ver, err := c.Client.GetVersion(ctx, packageID, version)
+	if err != nil {
+		return nil, err
+	}
+	if ver == nil {
+		c.versions.set(key, negativeValue{}, c.negativeTTL)
+		return nil, nil
+	}
+
+	c.versions.set(key, ver, c.versionTTL)
+	return ver, nil
+}
+
+func (c *CachingClient) ListVersions(ctx context.Context, packageID primitive.ObjectID, sortSemver bool, includeYanked bool) ([]models.Version, error) {
+	key := fmt.Sprintf("%slist:%t:%t", versionPrefix(packageID), sortSemver, includeYanked)
+	if cached, ok := c.versions.get(key); ok {
+		return cached.([]models.Version), nil
+	}
+
+	versions, err := c.Client.ListVersions(ctx, packageID, sortSemver, includeYanked)
+	if err != nil {
+		return nil, err
+	}
+
+	c.versions.set(key, versions, c.versionTTL)
+	return versions, nil
+}
+
+func (c *CachingClient) CreatePackage(ctx context.Context, pkg *models.Package, actor string) error {
+	if err := c.Client.CreatePackage(ctx, pkg, actor); err != nil {
+		return err
+	}
+	c.packages.deletePrefix("name:" + pkg.Name)
+	return nil
+}
+
+func (c *CachingClient) UpdatePackage(ctx context.Context, pkg *models.Package, actor string) error {
+	if err := c.Client.UpdatePackage(ctx, pkg, actor); err != nil {
+		return err
+	}
+	c.packages.deletePrefix("name:" + pkg.Name)
+	return nil
+}
+
+func (c *CachingClient) CreateVersion(ctx context.Context, version *models.Version, actor string) error {
+	if err := c.Client.CreateVersion(ctx, version, actor); err != nil {
+		return err
+	}
+	c.versions.deletePrefix(versionPrefix(version.PackageID))
+	return nil
+}
+
+func (c *CachingClient) YankVersion(ctx context.Context, packageID primitive.ObjectID, version string, actor string, reason string) error {
+	if err := c.Client.YankVersion(ctx, packageID, version, actor, reason); err != nil {
+		return err
+	}
+	c.versions.deletePrefix(versionPrefix(packageID))
+	return nil
+}
+
+func (c *CachingClient) UnyankVersion(ctx context.Context, packageID primitive.ObjectID, version string, actor string) error {
+	if err := c.Client.UnyankVersion(ctx, packageID, version, actor); err != nil {
+		return err
+	}
+	c.versions.deletePrefix(versionPrefix(packageID))
+	return nil
+}
+
+func (c *CachingClient) PurgeVersion(ctx context.Context, packageID primitive.ObjectID, version string, actor string, gracePeriod time.Duration) error {
+	if err := c.Client.PurgeVersion(ctx, packageID, version, actor, gracePeriod); err != nil {
+		return err
+	}
+	c.versions.deletePrefix(versionPrefix(packageID))
+	return nil
+}
+
+func (c *CachingClient) AddFileToVersion(ctx context.Context, packageID primitive.ObjectID, version string, file models.File, actor string) error {
+	if err := c.Client.AddFileToVersion(ctx, packageID, version, file, actor); err != nil {
+		return err
+	}
+	c.versions.deletePrefix(versionPrefix(packageID))
+	return nil
+}
+
+func (c *CachingClient) RemoveFileFromVersion(ctx context.Context, packageID primitive.ObjectID, version string, filename string, actor string) error {
+	if err := c.Client.RemoveFileFromVersion(ctx, packageID, version, filename, actor); err != nil {
+		return err
+	}
+	c.versions.deletePrefix(versionPrefix(packageID))
+	return nil
+}
+
+func (c *CachingClient) SetTag(ctx context.Context, packageID primitive.ObjectID, tag string, version string) error {
+	if err := c.Client.SetTag(ctx, packageID, tag, version); err != nil {
+		return err
+	}
+	c.invalidatePackageByID(ctx, packageID)
+	return nil
+}
+
+func (c *CachingClient) RemoveTag(ctx context.Context, packageID primitive.ObjectID, tag string) error {
+	if err := c.Client.RemoveTag(ctx, packageID, tag); err != nil {
+		return err
+	}
+	c.invalidatePackageByID(ctx, packageID)
+	return nil
+}
+
+// invalidatePackageByID drops packageID's GetPackage entry from the cache.
+// The package cache is keyed by name (GetPackage's only lookup key), so a
+// mutation that only has the ID, like SetTag/RemoveTag, needs this extra
+// uncached lookup to find the name to invalidate; it's a rare admin-driven
+// write, not the hot path this cache exists for.
+func (c *CachingClient) invalidatePackageByID(ctx context.Context, packageID primitive.ObjectID) {
+	pkg, err := c.Client.GetPackageByID(ctx, packageID)
+	if err != nil || pkg == nil {
+		return
+	}
+	c.packages.deletePrefix("name:" + pkg.Name)
+}
+
+// ResolveVersion reimplements Client.ResolveVersion against CachingClient's
+// own GetPackage/GetVersion/ListVersions rather than inheriting Client's,
+// since Go embedding doesn't give virtual dispatch: calling the embedded
+// method directly would have every lookup on this hot path (every install
+// resolves a version) bypass the cache entirely.
+func (c *CachingClient) ResolveVersion(ctx context.Context, pkgName string, spec string) (*models.Version, error) {
+	pkg, err := c.GetPackage(ctx, pkgName)
+	if err != nil {
+		return nil, err
+	}
+	if pkg == nil {
+		return nil, nil
+	}
+
+	if ver, err := c.GetVersion(ctx, pkg.ID, spec); err != nil {
+		return nil, err
+	} else if ver != nil {
+		return ver, nil
+	}
+
+	if tagged, ok := pkg.Tags[spec]; ok {
+		return c.GetVersion(ctx, pkg.ID, tagged)
+	}
+
+	constraint, err := semver.ParseConstraint(spec)
+	if err != nil {
+		return nil, nil
+	}
+
+	versions, err := c.ListVersions(ctx, pkg.ID, true, false)
+	if err != nil {
+		return nil, err
+	}
+	for i := len(versions) - 1; i >= 0; i-- { // ListVersions is oldest-first
+		parsed, err := semver.Parse(versions[i].Version)
+		if err != nil {
+			continue
+		}
+		if constraint.Satisfies(parsed) {
+			return &versions[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// versionPrefix namespaces every version-cache key (both GetVersion and
+// ListVersions entries) under one package, so a single write to that
+// package's versions can drop all of them with one deletePrefix call.
+func versionPrefix(packageID primitive.ObjectID) string {
+	return packageID.Hex() + ":"
+}
@@ -0,0 +1,148 @@
+package mongodb
+
+import (
+	"context"
+	"time"
+
+	"github.com/Parallels/pd-ai-agents-registry/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	instancesCollection = "instances"
+	rolloutsCollection  = "rollouts"
+)
+
+// VersionCount is a single row of the check-in stats aggregation: how many
+// checked-in instances are currently reporting a given version.
+type VersionCount struct {
+	Version string `bson:"_id" json:"version"`
+	Count   int64  `bson:"count" json:"count"`
+}
+
+// UpsertInstance records a client check-in, identified by InstanceID. Fields
+// that only matter on first sight (CreatedAt) are set with $setOnInsert so
+// repeated check-ins from the same instance don't reset them.
+func (c *Client) UpsertInstance(ctx context.Context, instance *models.Instance) error {
+	collection := c.database.Collection(instancesCollection)
+
+	now := time.Now()
+	instance.LastSeenAt = now
+
+	_, err := collection.UpdateOne(ctx,
+		bson.M{"instance_id": instance.InstanceID},
+		bson.M{
+			"$set": bson.M{
+				"group_id":        instance.GroupID,
+				"channel":         instance.Channel,
+				"platform":        instance.Platform,
+				"arch":            instance.Arch,
+				"current_version": instance.CurrentVersion,
+				"last_seen_ip":    instance.LastSeenIP,
+				"last_seen_at":    instance.LastSeenAt,
+			},
+			"$setOnInsert": bson.M{"created_at": now},
+		},
+		options.Update().SetUpsert(true),
+	)
+	return err
+}
+
+// InstanceStats returns how many checked-in instances are reporting each
+// version, most popular first, for update-adoption observability.
+func (c *Client) InstanceStats(ctx context.Context) ([]VersionCount, error) {
+	collection := c.database.Collection(instancesCollection)
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: "$current_version"},
+			{Key: "count", Value: bson.D{{Key: "$sum", Value: 1}}},
+		}}},
+		{{Key: "$sort", Value: bson.D{{Key: "count", Value: -1}}}},
+	}
+
+	cursor, err := collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	counts := []VersionCount{}
+	if err := cursor.All(ctx, &counts); err != nil {
+		return nil, err
+	}
+
+	return counts, nil
+}
+
+// GetRollout looks up the rollout gating a (channel, platform, arch). It
+// returns nil, nil if no rollout has been configured yet, which callers
+// should treat as fully rolled out with an empty blacklist.
+func (c *Client) GetRollout(ctx context.Context, channel, platform, arch string) (*models.Rollout, error) {
+	collection := c.database.Collection(rolloutsCollection)
+
+	var rollout models.Rollout
+	err := collection.FindOne(ctx, bson.M{"channel": channel, "platform": platform, "arch": arch}).Decode(&rollout)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &rollout, nil
+}
+
+// SetRolloutPercentage creates or adjusts the rollout percentage for a
+// (channel, platform, arch), letting an operator promote a build gradually
+// (e.g. 5 -> 25 -> 100) without republishing artifacts.
+func (c *Client) SetRolloutPercentage(ctx context.Context, channel, platform, arch string, percentage int) error {
+	collection := c.database.Collection(rolloutsCollection)
+
+	now := time.Now()
+	_, err := collection.UpdateOne(ctx,
+		bson.M{"channel": channel, "platform": platform, "arch": arch},
+		bson.M{
+			"$set":         bson.M{"percentage": percentage, "updated_at": now},
+			"$setOnInsert": bson.M{"blacklist": []string{}, "created_at": now},
+		},
+		options.Update().SetUpsert(true),
+	)
+	return err
+}
+
+// AddBlacklistedVersion blacklists a specific version for a (channel,
+// platform, arch): an instance checking in while running it is not offered
+// an update, e.g. after a bad build is found mid-rollout.
+func (c *Client) AddBlacklistedVersion(ctx context.Context, channel, platform, arch, version string) error {
+	collection := c.database.Collection(rolloutsCollection)
+
+	now := time.Now()
+	_, err := collection.UpdateOne(ctx,
+		bson.M{"channel": channel, "platform": platform, "arch": arch},
+		bson.M{
+			"$addToSet":    bson.M{"blacklist": version},
+			"$set":         bson.M{"updated_at": now},
+			"$setOnInsert": bson.M{"percentage": 100, "created_at": now},
+		},
+		options.Update().SetUpsert(true),
+	)
+	return err
+}
+
+// RemoveBlacklistedVersion un-blocks a previously blacklisted version for a
+// (channel, platform, arch).
+func (c *Client) RemoveBlacklistedVersion(ctx context.Context, channel, platform, arch, version string) error {
+	collection := c.database.Collection(rolloutsCollection)
+
+	_, err := collection.UpdateOne(ctx,
+		bson.M{"channel": channel, "platform": platform, "arch": arch},
+		bson.M{
+			"$pull": bson.M{"blacklist": version},
+			"$set":  bson.M{"updated_at": time.Now()},
+		},
+	)
+	return err
+}
@@ -20,6 +20,31 @@ func (c *Client) EnsureIndexes(ctx context.Context) error {
 		return err
 	}
 
+	// Auth indexes
+	if err := c.createAuthIndexes(ctx); err != nil {
+		return err
+	}
+
+	// Update (self-update feed) indexes
+	if err := c.createUpdateIndexes(ctx); err != nil {
+		return err
+	}
+
+	// Check-in (instances and rollouts) indexes
+	if err := c.createCheckinIndexes(ctx); err != nil {
+		return err
+	}
+
+	// Release notes indexes
+	if err := c.createReleaseNotesIndexes(ctx); err != nil {
+		return err
+	}
+
+	// Resumable upload session indexes
+	if err := c.createBlobUploadIndexes(ctx); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -34,6 +59,168 @@ func (c *Client) createPackageIndexes(ctx context.Context) error {
 		{
 			Keys: bson.D{{Key: "created_at", Value: 1}},
 		},
+		{
+			// Backs the ?q= text search in ListPackages.
+			Keys: bson.D{
+				{Key: "name", Value: "text"},
+				{Key: "description", Value: "text"},
+				{Key: "categories", Value: "text"},
+			},
+		},
+		{
+			// Backs category browsing sorted by rating.
+			Keys: bson.D{
+				{Key: "categories", Value: 1},
+				{Key: "star_rating", Value: -1},
+			},
+		},
+	}
+
+	_, err := collection.Indexes().CreateMany(ctx, indexes)
+	return err
+}
+
+// createAuthIndexes backs refresh-token lookups and revocation checks, and
+// lets Mongo's TTL monitor reap expired refresh tokens and denylist
+// entries instead of the registry needing its own sweep job.
+func (c *Client) createAuthIndexes(ctx context.Context) error {
+	refreshTokens := c.database.Collection(refreshTokensCollection)
+	refreshIndexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "token_hash", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys:    bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		},
+	}
+	if _, err := refreshTokens.Indexes().CreateMany(ctx, refreshIndexes); err != nil {
+		return err
+	}
+
+	revokedTokens := c.database.Collection(revokedTokensCollection)
+	revokedIndexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "jti", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys:    bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		},
+	}
+	_, err := revokedTokens.Indexes().CreateMany(ctx, revokedIndexes)
+	return err
+}
+
+// updatesCollection mirrors the literal name the update handlers pass to
+// Database().Collection directly; the self-update feed predates the
+// repository pattern the rest of this package uses, so this is the only
+// place it's named as a constant.
+const updatesCollection = "updates"
+
+// createUpdateIndexes backs the version_major/minor/patch compound sort
+// GetLatestUpdate and ListUpdates use to rank versions correctly, instead
+// of the lexicographic ordering a plain "version" string index would give.
+// channel leads the compound index since every per-channel lookup filters
+// on it before sorting.
+func (c *Client) createUpdateIndexes(ctx context.Context) error {
+	collection := c.database.Collection(updatesCollection)
+
+	indexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "channel", Value: 1},
+				{Key: "platform", Value: 1},
+				{Key: "arch", Value: 1},
+				{Key: "version_major", Value: -1},
+				{Key: "version_minor", Value: -1},
+				{Key: "version_patch", Value: -1},
+				{Key: "version_is_release", Value: -1},
+				{Key: "version_prerelease", Value: -1},
+			},
+		},
+		{
+			Keys: bson.D{
+				{Key: "version", Value: 1},
+				{Key: "platform", Value: 1},
+				{Key: "arch", Value: 1},
+			},
+			Options: options.Index().SetUnique(true),
+		},
+	}
+
+	_, err := collection.Indexes().CreateMany(ctx, indexes)
+	return err
+}
+
+// createCheckinIndexes backs instance check-in upserts and rollout lookups:
+// a unique index on instances.instance_id so a client's repeated check-ins
+// update one document, and a unique compound index on rollouts so each
+// (channel, platform, arch) has at most one rollout document.
+func (c *Client) createCheckinIndexes(ctx context.Context) error {
+	instances := c.database.Collection(instancesCollection)
+	instanceIndexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "instance_id", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{{Key: "current_version", Value: 1}},
+		},
+	}
+	if _, err := instances.Indexes().CreateMany(ctx, instanceIndexes); err != nil {
+		return err
+	}
+
+	rollouts := c.database.Collection(rolloutsCollection)
+	rolloutIndexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "channel", Value: 1},
+				{Key: "platform", Value: 1},
+				{Key: "arch", Value: 1},
+			},
+			Options: options.Index().SetUnique(true),
+		},
+	}
+	_, err := rollouts.Indexes().CreateMany(ctx, rolloutIndexes)
+	return err
+}
+
+// createReleaseNotesIndexes backs UpsertReleaseNotes/GetReleaseNotes with a
+// unique compound index, so each (channel, version) has at most one notes
+// document.
+func (c *Client) createReleaseNotesIndexes(ctx context.Context) error {
+	collection := c.database.Collection(releaseNotesCollection)
+
+	indexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "channel", Value: 1},
+				{Key: "version", Value: 1},
+			},
+			Options: options.Index().SetUnique(true),
+		},
+	}
+
+	_, err := collection.Indexes().CreateMany(ctx, indexes)
+	return err
+}
+
+// createBlobUploadIndexes backs the sweeper's expired-session scan. There's
+// no TTL index here unlike the auth collections: the sweeper needs to read
+// a session's chunk_hashes to decide whether to garbage-collect them before
+// the document is removed, so expiry has to go through application code
+// rather than Mongo's background TTL reaper.
+func (c *Client) createBlobUploadIndexes(ctx context.Context) error {
+	collection := c.database.Collection(blobUploadsCollection)
+
+	indexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "expires_at", Value: 1}},
+		},
 	}
 
 	_, err := collection.Indexes().CreateMany(ctx, indexes)
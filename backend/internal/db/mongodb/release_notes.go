@@ -0,0 +1,48 @@
+package mongodb
+
+import (
+	"context"
+	"time"
+
+	"github.com/Parallels/pd-ai-agents-registry/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const releaseNotesCollection = "release_notes"
+
+// UpsertReleaseNotes stores (or replaces) the raw Markdown release notes for
+// a (channel, version), so re-uploading an update can correct a typo in the
+// notes without needing a new version.
+func (c *Client) UpsertReleaseNotes(ctx context.Context, channel, version, markdown string) error {
+	collection := c.database.Collection(releaseNotesCollection)
+
+	now := time.Now()
+	_, err := collection.UpdateOne(ctx,
+		bson.M{"channel": channel, "version": version},
+		bson.M{
+			"$set":         bson.M{"markdown": markdown, "updated_at": now},
+			"$setOnInsert": bson.M{"created_at": now},
+		},
+		options.Update().SetUpsert(true),
+	)
+	return err
+}
+
+// GetReleaseNotes looks up the release notes for a (channel, version). It
+// returns nil, nil if no notes were published for that release.
+func (c *Client) GetReleaseNotes(ctx context.Context, channel, version string) (*models.ReleaseNotes, error) {
+	collection := c.database.Collection(releaseNotesCollection)
+
+	var notes models.ReleaseNotes
+	err := collection.FindOne(ctx, bson.M{"channel": channel, "version": version}).Decode(&notes)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &notes, nil
+}
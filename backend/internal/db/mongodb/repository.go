@@ -2,35 +2,140 @@ package mongodb
 
 import (
 	"context"
+	"fmt"
+	"sort"
 	"time"
 
 	"github.com/Parallels/pd-ai-agents-registry/internal/models"
+	"github.com/Parallels/pd-ai-agents-registry/internal/semver"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 const (
 	packagesCollection = "packages"
 	versionsCollection = "versions"
+	chunksCollection   = "chunks"
 )
 
-// ListPackages retrieves all packages from the database
-func (c *Client) ListPackages(ctx context.Context) ([]models.Package, error) {
+const (
+	defaultPage    = 1
+	defaultPerPage = 20
+	maxPerPage     = 100
+)
+
+// ListPackagesFilter narrows and orders the results of ListPackages. The
+// zero value returns the first page of every package, sorted by name.
+type ListPackagesFilter struct {
+	Query      string
+	Category   string
+	Author     string
+	IsOfficial *bool
+	Sort       string // name|created_at|star_rating
+	Order      string // asc|desc
+	Page       int
+	PerPage    int
+}
+
+// CategoryCount is a single row of the /packages/categories aggregation.
+type CategoryCount struct {
+	Category string `json:"category" bson:"_id"`
+	Count    int64  `json:"count" bson:"count"`
+}
+
+// ListPackages retrieves a filtered, sorted page of packages along with the
+// total number of packages matching the filter.
+func (c *Client) ListPackages(ctx context.Context, filter ListPackagesFilter) ([]models.Package, int64, error) {
 	collection := c.database.Collection(packagesCollection)
 
-	cursor, err := collection.Find(ctx, bson.M{})
+	query := bson.M{}
+	if filter.Query != "" {
+		query["$text"] = bson.M{"$search": filter.Query}
+	}
+	if filter.Category != "" {
+		query["categories"] = filter.Category
+	}
+	if filter.Author != "" {
+		query["author"] = filter.Author
+	}
+	if filter.IsOfficial != nil {
+		query["is_official"] = *filter.IsOfficial
+	}
+
+	total, err := collection.CountDocuments(ctx, query)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
+	}
+
+	page := filter.Page
+	if page < 1 {
+		page = defaultPage
+	}
+	perPage := filter.PerPage
+	if perPage < 1 {
+		perPage = defaultPerPage
+	}
+	if perPage > maxPerPage {
+		perPage = maxPerPage
+	}
+
+	sortField := "name"
+	switch filter.Sort {
+	case "created_at", "star_rating":
+		sortField = filter.Sort
+	}
+	sortOrder := 1
+	if filter.Order == "desc" {
+		sortOrder = -1
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: sortField, Value: sortOrder}}).
+		SetSkip(int64((page - 1) * perPage)).
+		SetLimit(int64(perPage))
+
+	cursor, err := collection.Find(ctx, query, opts)
+	if err != nil {
+		return nil, 0, err
 	}
 	defer cursor.Close(ctx)
 
-	var packages []models.Package
+	packages := []models.Package{}
 	if err = cursor.All(ctx, &packages); err != nil {
+		return nil, 0, err
+	}
+
+	return packages, total, nil
+}
+
+// ListCategories returns every distinct package category along with how
+// many packages carry it, for category-browsing UIs.
+func (c *Client) ListCategories(ctx context.Context) ([]CategoryCount, error) {
+	collection := c.database.Collection(packagesCollection)
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$unwind", Value: "$categories"}},
+		{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: "$categories"},
+			{Key: "count", Value: bson.D{{Key: "$sum", Value: 1}}},
+		}}},
+		{{Key: "$sort", Value: bson.D{{Key: "_id", Value: 1}}}},
+	}
+
+	cursor, err := collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	categories := []CategoryCount{}
+	if err := cursor.All(ctx, &categories); err != nil {
 		return nil, err
 	}
 
-	return packages, nil
+	return categories, nil
 }
 
 // GetPackage retrieves a specific package by name
@@ -49,36 +154,176 @@ func (c *Client) GetPackage(ctx context.Context, name string) (*models.Package,
 	return &pkg, nil
 }
 
+// GetPackageByID retrieves a specific package by its ObjectID.
+func (c *Client) GetPackageByID(ctx context.Context, id primitive.ObjectID) (*models.Package, error) {
+	collection := c.database.Collection(packagesCollection)
+
+	var pkg models.Package
+	err := collection.FindOne(ctx, bson.M{"_id": id}).Decode(&pkg)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &pkg, nil
+}
+
 // CreatePackage creates a new package
-func (c *Client) CreatePackage(ctx context.Context, pkg *models.Package) error {
+func (c *Client) CreatePackage(ctx context.Context, pkg *models.Package, actor string) error {
 	collection := c.database.Collection(packagesCollection)
 
 	pkg.CreatedAt = time.Now()
 	pkg.UpdatedAt = time.Now()
 
-	_, err := collection.InsertOne(ctx, pkg)
-	return err
+	if _, err := collection.InsertOne(ctx, pkg); err != nil {
+		return err
+	}
+
+	// Best-effort: see CreateVersion's InvalidateRepositoryIndexes comment;
+	// a lost audit entry doesn't undo the write, it just leaves a gap in
+	// the trail.
+	_ = c.LogAudit(ctx, models.AuditEntry{Actor: actor, Action: models.AuditActionCreatePackage, Target: pkg.Name, After: pkg})
+	return nil
 }
 
 // UpdatePackage updates an existing package
-func (c *Client) UpdatePackage(ctx context.Context, pkg *models.Package) error {
+func (c *Client) UpdatePackage(ctx context.Context, pkg *models.Package, actor string) error {
 	collection := c.database.Collection(packagesCollection)
 
+	before, err := c.GetPackageByID(ctx, pkg.ID)
+	if err != nil {
+		return err
+	}
+
 	pkg.UpdatedAt = time.Now()
 
-	_, err := collection.ReplaceOne(
+	if _, err := collection.ReplaceOne(
 		ctx,
 		bson.M{"_id": pkg.ID},
 		pkg,
+	); err != nil {
+		return err
+	}
+
+	// Best-effort: see CreateVersion.
+	_ = c.LogAudit(ctx, models.AuditEntry{Actor: actor, Action: models.AuditActionUpdatePackage, Target: pkg.Name, Before: before, After: pkg})
+	return nil
+}
+
+// SetTag points a dist-tag (e.g. "latest", "beta") at version on packageID's
+// document, creating it or overwriting whatever version it previously
+// pointed at.
+func (c *Client) SetTag(ctx context.Context, packageID primitive.ObjectID, tag string, version string) error {
+	collection := c.database.Collection(packagesCollection)
+
+	_, err := collection.UpdateOne(
+		ctx,
+		bson.M{"_id": packageID},
+		bson.M{"$set": bson.M{
+			"tags." + tag: version,
+			"updated_at":  time.Now(),
+		}},
+	)
+	return err
+}
+
+// RemoveTag deletes a dist-tag from packageID's document.
+func (c *Client) RemoveTag(ctx context.Context, packageID primitive.ObjectID, tag string) error {
+	collection := c.database.Collection(packagesCollection)
+
+	_, err := collection.UpdateOne(
+		ctx,
+		bson.M{"_id": packageID},
+		bson.M{
+			"$unset": bson.M{"tags." + tag: ""},
+			"$set":   bson.M{"updated_at": time.Now()},
+		},
 	)
 	return err
 }
 
-// ListVersions retrieves all versions for a specific package
-func (c *Client) ListVersions(ctx context.Context, packageID primitive.ObjectID) ([]models.Version, error) {
+// ListTags returns every dist-tag set on packageID's document.
+func (c *Client) ListTags(ctx context.Context, packageID primitive.ObjectID) (map[string]string, error) {
+	pkg, err := c.GetPackageByID(ctx, packageID)
+	if err != nil {
+		return nil, err
+	}
+	if pkg == nil {
+		return nil, nil
+	}
+	return pkg.Tags, nil
+}
+
+// ResolveVersion resolves spec against pkgName's versions, trying each of
+// the following in order and returning the first match: an exact version
+// string, a dist-tag name (see SetTag), and finally a semver constraint
+// such as "^1.2.0" or ">=2.0.0 <3.0.0", in which case the highest version
+// satisfying it is returned. It returns (nil, nil), consistent with
+// GetPackage/GetVersion, if the package doesn't exist or spec matches
+// nothing.
+func (c *Client) ResolveVersion(ctx context.Context, pkgName string, spec string) (*models.Version, error) {
+	pkg, err := c.GetPackage(ctx, pkgName)
+	if err != nil {
+		return nil, err
+	}
+	if pkg == nil {
+		return nil, nil
+	}
+
+	if ver, err := c.GetVersion(ctx, pkg.ID, spec); err != nil {
+		return nil, err
+	} else if ver != nil {
+		return ver, nil
+	}
+
+	if tagged, ok := pkg.Tags[spec]; ok {
+		return c.GetVersion(ctx, pkg.ID, tagged)
+	}
+
+	constraint, err := semver.ParseConstraint(spec)
+	if err != nil {
+		// Not an exact version, not a tag, and not a valid range: nothing to
+		// resolve.
+		return nil, nil
+	}
+
+	// A constraint range isn't an explicit version, so it resolves against
+	// the same default (yanked-excluded) set ListVersions returns.
+	versions, err := c.ListVersions(ctx, pkg.ID, true, false)
+	if err != nil {
+		return nil, err
+	}
+	for i := len(versions) - 1; i >= 0; i-- { // ListVersions is oldest-first
+		parsed, err := semver.Parse(versions[i].Version)
+		if err != nil {
+			continue
+		}
+		if constraint.Satisfies(parsed) {
+			return &versions[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// ListVersions retrieves the versions for a specific package. When
+// sortSemver is true, versions are ordered oldest-to-newest by parsed
+// SemVer precedence rather than insertion order; versions whose Version
+// string doesn't parse as SemVer sort after every version that does. When
+// includeYanked is false (the default for callers resolving what to
+// install), yanked versions are left out entirely, matching how Cargo and
+// npm treat a yank; GetVersion is unaffected, so an exact version stays
+// resolvable even while yanked.
+func (c *Client) ListVersions(ctx context.Context, packageID primitive.ObjectID, sortSemver bool, includeYanked bool) ([]models.Version, error) {
 	collection := c.database.Collection(versionsCollection)
 
-	cursor, err := collection.Find(ctx, bson.M{"package_id": packageID})
+	filter := bson.M{"package_id": packageID}
+	if !includeYanked {
+		filter["yanked"] = bson.M{"$ne": true}
+	}
+
+	cursor, err := collection.Find(ctx, filter)
 	if err != nil {
 		return nil, err
 	}
@@ -89,6 +334,17 @@ func (c *Client) ListVersions(ctx context.Context, packageID primitive.ObjectID)
 		return nil, err
 	}
 
+	if sortSemver {
+		sort.SliceStable(versions, func(i, j int) bool {
+			vi, erri := semver.Parse(versions[i].Version)
+			vj, errj := semver.Parse(versions[j].Version)
+			if erri != nil || errj != nil {
+				return erri == nil // unparsable versions sort last
+			}
+			return vi.Compare(vj) < 0
+		})
+	}
+
 	return versions, nil
 }
 
@@ -112,31 +368,147 @@ func (c *Client) GetVersion(ctx context.Context, packageID primitive.ObjectID, v
 }
 
 // CreateVersion creates a new version for a package
-func (c *Client) CreateVersion(ctx context.Context, version *models.Version) error {
+func (c *Client) CreateVersion(ctx context.Context, version *models.Version, actor string) error {
 	collection := c.database.Collection(versionsCollection)
 
 	version.CreatedAt = time.Now()
 
-	_, err := collection.InsertOne(ctx, version)
-	return err
+	if _, err := collection.InsertOne(ctx, version); err != nil {
+		return err
+	}
+
+	// Best-effort: a stale repository index just costs the next request an
+	// extra regeneration, so this never fails the write itself.
+	_ = c.InvalidateRepositoryIndexes(ctx)
+	_ = c.LogAudit(ctx, models.AuditEntry{Actor: actor, Action: models.AuditActionCreateVersion, Target: auditVersionTarget(version.PackageID, version.Version), After: version})
+	return nil
+}
+
+// YankVersion marks version as yanked: GetVersion and an exact download
+// still resolve it, but ListVersions and ResolveVersion's default
+// (non-exact) results leave it out, the way Cargo and npm handle a yank
+// instead of deleting the version outright.
+func (c *Client) YankVersion(ctx context.Context, packageID primitive.ObjectID, version string, actor string, reason string) error {
+	collection := c.database.Collection(versionsCollection)
+
+	before, err := c.GetVersion(ctx, packageID, version)
+	if err != nil {
+		return err
+	}
+	if before == nil {
+		return mongo.ErrNoDocuments
+	}
+
+	now := time.Now()
+	if _, err := collection.UpdateOne(
+		ctx,
+		bson.M{"package_id": packageID, "version": version},
+		bson.M{"$set": bson.M{
+			"yanked":      true,
+			"yanked_at":   now,
+			"yanked_by":   actor,
+			"yank_reason": reason,
+		}},
+	); err != nil {
+		return err
+	}
+
+	after := *before
+	after.Yanked = true
+	after.YankedAt = &now
+	after.YankedBy = actor
+	after.YankReason = reason
+
+	// Best-effort: see CreateVersion.
+	_ = c.InvalidateRepositoryIndexes(ctx)
+	_ = c.LogAudit(ctx, models.AuditEntry{Actor: actor, Action: models.AuditActionYankVersion, Target: auditVersionTarget(packageID, version), Before: before, After: after})
+	return nil
+}
+
+// UnyankVersion reverses YankVersion, making version resolvable again
+// through ListVersions and ResolveVersion's default results.
+func (c *Client) UnyankVersion(ctx context.Context, packageID primitive.ObjectID, version string, actor string) error {
+	collection := c.database.Collection(versionsCollection)
+
+	before, err := c.GetVersion(ctx, packageID, version)
+	if err != nil {
+		return err
+	}
+	if before == nil {
+		return mongo.ErrNoDocuments
+	}
+
+	if _, err := collection.UpdateOne(
+		ctx,
+		bson.M{"package_id": packageID, "version": version},
+		bson.M{
+			"$set":   bson.M{"yanked": false},
+			"$unset": bson.M{"yanked_at": "", "yanked_by": "", "yank_reason": ""},
+		},
+	); err != nil {
+		return err
+	}
+
+	after := *before
+	after.Yanked = false
+	after.YankedAt = nil
+	after.YankedBy = ""
+	after.YankReason = ""
+
+	// Best-effort: see CreateVersion.
+	_ = c.InvalidateRepositoryIndexes(ctx)
+	_ = c.LogAudit(ctx, models.AuditEntry{Actor: actor, Action: models.AuditActionUnyankVersion, Target: auditVersionTarget(packageID, version), Before: before, After: after})
+	return nil
 }
 
-// DeleteVersion deletes a specific version of a package
-func (c *Client) DeleteVersion(ctx context.Context, packageID primitive.ObjectID, version string) error {
+// PurgeVersion permanently deletes version. It only succeeds once version
+// has been yanked for at least gracePeriod, so a hard delete can't erase a
+// version before downstream mirrors and lockfiles had a chance to notice
+// the yank; callers that need the old unconditional delete should yank
+// first and purge once the grace period (config.VersionsConfig) has
+// passed.
+func (c *Client) PurgeVersion(ctx context.Context, packageID primitive.ObjectID, version string, actor string, gracePeriod time.Duration) error {
 	collection := c.database.Collection(versionsCollection)
 
-	_, err := collection.DeleteOne(ctx, bson.M{
+	before, err := c.GetVersion(ctx, packageID, version)
+	if err != nil {
+		return err
+	}
+	if before == nil {
+		return mongo.ErrNoDocuments
+	}
+	if !before.Yanked {
+		return fmt.Errorf("mongodb: version %s must be yanked before it can be purged", version)
+	}
+	if before.YankedAt == nil || time.Since(*before.YankedAt) < gracePeriod {
+		return fmt.Errorf("mongodb: version %s was yanked less than %s ago, refusing to purge", version, gracePeriod)
+	}
+
+	if _, err := collection.DeleteOne(ctx, bson.M{
 		"package_id": packageID,
 		"version":    version,
-	})
-	return err
+	}); err != nil {
+		return err
+	}
+
+	// Best-effort: see CreateVersion.
+	_ = c.InvalidateRepositoryIndexes(ctx)
+	_ = c.LogAudit(ctx, models.AuditEntry{Actor: actor, Action: models.AuditActionPurgeVersion, Target: auditVersionTarget(packageID, version), Before: before})
+	return nil
+}
+
+// auditVersionTarget formats an audit log Target identifying a single
+// version, since the low-level Client methods only have a packageID (not
+// the package's name) to hand.
+func auditVersionTarget(packageID primitive.ObjectID, version string) string {
+	return packageID.Hex() + "@" + version
 }
 
 // AddFileToVersion adds a file to a specific version
-func (c *Client) AddFileToVersion(ctx context.Context, packageID primitive.ObjectID, version string, file models.File) error {
+func (c *Client) AddFileToVersion(ctx context.Context, packageID primitive.ObjectID, version string, file models.File, actor string) error {
 	collection := c.database.Collection(versionsCollection)
 
-	_, err := collection.UpdateOne(
+	if _, err := collection.UpdateOne(
 		ctx,
 		bson.M{
 			"package_id": packageID,
@@ -145,15 +517,80 @@ func (c *Client) AddFileToVersion(ctx context.Context, packageID primitive.Objec
 		bson.M{
 			"$push": bson.M{"files": file},
 		},
+	); err != nil {
+		return err
+	}
+
+	// Best-effort: see CreateVersion.
+	_ = c.InvalidateRepositoryIndexes(ctx)
+	_ = c.LogAudit(ctx, models.AuditEntry{Actor: actor, Action: models.AuditActionAddFile, Target: auditVersionTarget(packageID, version), After: file})
+	return nil
+}
+
+// SetVersionProcessingState updates the async processing status recorded on
+// a version, along with the error message when it failed.
+func (c *Client) SetVersionProcessingState(ctx context.Context, packageID primitive.ObjectID, version string, state string, processingError string) error {
+	collection := c.database.Collection(versionsCollection)
+
+	update := bson.M{"processing_state": state}
+	if processingError == "" {
+		update["processing_error"] = ""
+	} else {
+		update["processing_error"] = processingError
+	}
+
+	_, err := collection.UpdateOne(
+		ctx,
+		bson.M{
+			"package_id": packageID,
+			"version":    version,
+		},
+		bson.M{"$set": update},
 	)
 	return err
 }
 
-// RemoveFileFromVersion removes a file from a specific version
-func (c *Client) RemoveFileFromVersion(ctx context.Context, packageID primitive.ObjectID, version string, filename string) error {
+// ApplyManifest stores the requirements and agent definitions extracted from
+// an uploaded package archive onto its version.
+func (c *Client) ApplyManifest(ctx context.Context, packageID primitive.ObjectID, version string, requirements []string, agents []models.AgentDefinition) error {
+	collection := c.database.Collection(versionsCollection)
+
+	_, err := collection.UpdateOne(
+		ctx,
+		bson.M{
+			"package_id": packageID,
+			"version":    version,
+		},
+		bson.M{"$set": bson.M{
+			"requirements": requirements,
+			"agents":       agents,
+		}},
+	)
+	return err
+}
+
+// SetFileSignature records a verified detached signature against a single
+// file within a version.
+func (c *Client) SetFileSignature(ctx context.Context, packageID primitive.ObjectID, version string, filename string, signature models.FileSignature) error {
 	collection := c.database.Collection(versionsCollection)
 
 	_, err := collection.UpdateOne(
+		ctx,
+		bson.M{
+			"package_id": packageID,
+			"version":    version,
+			"files.name": filename,
+		},
+		bson.M{"$set": bson.M{"files.$.signature": signature}},
+	)
+	return err
+}
+
+// RemoveFileFromVersion removes a file from a specific version
+func (c *Client) RemoveFileFromVersion(ctx context.Context, packageID primitive.ObjectID, version string, filename string, actor string) error {
+	collection := c.database.Collection(versionsCollection)
+
+	if _, err := collection.UpdateOne(
 		ctx,
 		bson.M{
 			"package_id": packageID,
@@ -164,6 +601,147 @@ func (c *Client) RemoveFileFromVersion(ctx context.Context, packageID primitive.
 				"files": bson.M{"name": filename},
 			},
 		},
-	)
-	return err
+	); err != nil {
+		return err
+	}
+
+	// Best-effort: see CreateVersion.
+	_ = c.InvalidateRepositoryIndexes(ctx)
+	_ = c.LogAudit(ctx, models.AuditEntry{Actor: actor, Action: models.AuditActionRemoveFile, Target: auditVersionTarget(packageID, version), Before: filename})
+	return nil
+}
+
+// CountVersions returns how many versions exist for packageID, so the
+// retention cleanup worker can decide whether a "keep last N" rule even
+// applies before paging through any documents.
+func (c *Client) CountVersions(ctx context.Context, packageID primitive.ObjectID) (int64, error) {
+	collection := c.database.Collection(versionsCollection)
+
+	return collection.CountDocuments(ctx, bson.M{"package_id": packageID})
+}
+
+// ListVersionsOlderThan returns the versions of packageID created before
+// cutoff, sorted oldest first, for the retention cleanup worker's "delete
+// versions older than duration T" rule.
+func (c *Client) ListVersionsOlderThan(ctx context.Context, packageID primitive.ObjectID, cutoff time.Time) ([]models.Version, error) {
+	collection := c.database.Collection(versionsCollection)
+
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}})
+	cursor, err := collection.Find(ctx, bson.M{
+		"package_id": packageID,
+		"created_at": bson.M{"$lt": cutoff},
+	}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var versions []models.Version
+	if err := cursor.All(ctx, &versions); err != nil {
+		return nil, err
+	}
+
+	return versions, nil
+}
+
+// ListVersionsBeyondLast returns the versions of packageID that fall outside
+// the keepLastN most recently created ones, for the retention cleanup
+// worker's "keep last N versions" rule. It sorts and skips in the database
+// rather than pulling every version into memory to sort client-side.
+func (c *Client) ListVersionsBeyondLast(ctx context.Context, packageID primitive.ObjectID, keepLastN int) ([]models.Version, error) {
+	collection := c.database.Collection(versionsCollection)
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}}).
+		SetSkip(int64(keepLastN))
+	cursor, err := collection.Find(ctx, bson.M{"package_id": packageID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var versions []models.Version
+	if err := cursor.All(ctx, &versions); err != nil {
+		return nil, err
+	}
+
+	return versions, nil
+}
+
+// DeleteVersionsBulk deletes every version whose _id is in ids in a single
+// request, so the retention cleanup worker can prune a package's expired
+// versions without one round trip per document.
+func (c *Client) DeleteVersionsBulk(ctx context.Context, ids []primitive.ObjectID) (int64, error) {
+	collection := c.database.Collection(versionsCollection)
+
+	res, err := collection.DeleteMany(ctx, bson.M{"_id": bson.M{"$in": ids}})
+	if err != nil {
+		return 0, err
+	}
+
+	// Best-effort: see CreateVersion.
+	_ = c.InvalidateRepositoryIndexes(ctx)
+	return res.DeletedCount, nil
+}
+
+// RetainChunks records one reference per distinct hash in chunkSizes (a
+// file that repeats a chunk still adds a single reference to it) against
+// the chunks collection, creating the chunk document on first reference. It's called once per uploaded file, after every chunk
+// has already been written to the storage backend.
+func (c *Client) RetainChunks(ctx context.Context, chunkSizes map[string]int64) error {
+	collection := c.database.Collection(chunksCollection)
+
+	for hash, size := range chunkSizes {
+		_, err := collection.UpdateOne(
+			ctx,
+			bson.M{"_id": hash},
+			bson.M{
+				"$inc":         bson.M{"ref_count": 1},
+				"$setOnInsert": bson.M{"size": size, "created_at": time.Now()},
+			},
+			options.Update().SetUpsert(true),
+		)
+		if err != nil {
+			return fmt.Errorf("failed to retain chunk %s: %w", hash, err)
+		}
+	}
+
+	return nil
+}
+
+// ReleaseChunks drops one reference per distinct hash in hashes, matching
+// what RetainChunks added for the file, and returns the hashes whose ref count reached zero, so the caller can GC
+// the corresponding blobs from storage and remove their chunk documents.
+func (c *Client) ReleaseChunks(ctx context.Context, hashes []string) ([]string, error) {
+	collection := c.database.Collection(chunksCollection)
+
+	counts := make(map[string]int64, len(hashes))
+	for _, hash := range hashes {
+		counts[hash] = 1
+	}
+
+	var drained []string
+	for hash, n := range counts {
+		var chunk models.Chunk
+		err := collection.FindOneAndUpdate(
+			ctx,
+			bson.M{"_id": hash},
+			bson.M{"$inc": bson.M{"ref_count": -n}},
+			options.FindOneAndUpdate().SetReturnDocument(options.After),
+		).Decode(&chunk)
+		if err != nil {
+			if err == mongo.ErrNoDocuments {
+				continue
+			}
+			return nil, fmt.Errorf("failed to release chunk %s: %w", hash, err)
+		}
+		if chunk.RefCount <= 0 {
+			if _, err := collection.DeleteOne(ctx, bson.M{"_id": hash}); err != nil {
+				return nil, fmt.Errorf("failed to remove drained chunk %s: %w", hash, err)
+			}
+			drained = append(drained, hash)
+		}
+	}
+
+	return drained, nil
 }
@@ -0,0 +1,71 @@
+package mongodb
+
+import (
+	"context"
+	"time"
+
+	"github.com/Parallels/pd-ai-agents-registry/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const repositoryIndexesCollection = "repository_indexes"
+
+// repositoryIndexID is the cache key a generated index is stored under:
+// one document per format, replaced wholesale whenever the catalog state
+// (InputHash) it was built from changes.
+func repositoryIndexID(format string) string {
+	return format
+}
+
+// GetCachedIndex returns the cached index for format if it was built from
+// the catalog state identified by inputHash, or (nil, nil) if nothing is
+// cached or the cached entry is stale.
+func (c *Client) GetCachedIndex(ctx context.Context, format, inputHash string) (*models.RepositoryIndex, error) {
+	collection := c.database.Collection(repositoryIndexesCollection)
+
+	var index models.RepositoryIndex
+	err := collection.FindOne(ctx, bson.M{"_id": repositoryIndexID(format)}).Decode(&index)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if index.InputHash != inputHash {
+		return nil, nil
+	}
+
+	return &index, nil
+}
+
+// PutCachedIndex stores a newly generated index for format, replacing
+// whatever was previously cached for it.
+func (c *Client) PutCachedIndex(ctx context.Context, format, inputHash string, files map[string][]byte) error {
+	collection := c.database.Collection(repositoryIndexesCollection)
+
+	index := models.RepositoryIndex{
+		ID:          repositoryIndexID(format),
+		Format:      format,
+		InputHash:   inputHash,
+		Files:       files,
+		GeneratedAt: time.Now(),
+	}
+
+	_, err := collection.ReplaceOne(ctx, bson.M{"_id": index.ID}, index, options.Replace().SetUpsert(true))
+	return err
+}
+
+// InvalidateRepositoryIndexes drops every cached index, so the next request
+// for any format regenerates it from the current catalog. It's called from
+// every mutation of versions or their files (CreateVersion, YankVersion,
+// UnyankVersion, PurgeVersion, DeleteVersionsBulk, AddFileToVersion,
+// RemoveFileFromVersion), rather than trying to reason about which formats
+// a given change could have affected.
+func (c *Client) InvalidateRepositoryIndexes(ctx context.Context) error {
+	collection := c.database.Collection(repositoryIndexesCollection)
+
+	_, err := collection.DeleteMany(ctx, bson.M{})
+	return err
+}
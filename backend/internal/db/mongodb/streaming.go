@@ -0,0 +1,62 @@
+package mongodb
+
+import (
+	"context"
+
+	"github.com/Parallels/pd-ai-agents-registry/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// StreamPackages calls fn once per package in the registry, in no
+// particular order, decoding one document at a time instead of buffering
+// every package the way ListPackages does. It's meant for whole-catalog
+// consumers like the repository index generators, where the catalog may be
+// far larger than a single page. Iteration stops at the first error fn
+// returns.
+func (c *Client) StreamPackages(ctx context.Context, fn func(models.Package) error) error {
+	collection := c.database.Collection(packagesCollection)
+
+	cursor, err := collection.Find(ctx, bson.M{})
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var pkg models.Package
+		if err := cursor.Decode(&pkg); err != nil {
+			return err
+		}
+		if err := fn(pkg); err != nil {
+			return err
+		}
+	}
+
+	return cursor.Err()
+}
+
+// StreamVersions calls fn once per version of packageID, in no particular
+// order, decoding one document at a time instead of buffering every version
+// the way ListVersions does.
+func (c *Client) StreamVersions(ctx context.Context, packageID primitive.ObjectID, fn func(models.Version) error) error {
+	collection := c.database.Collection(versionsCollection)
+
+	cursor, err := collection.Find(ctx, bson.M{"package_id": packageID})
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var ver models.Version
+		if err := cursor.Decode(&ver); err != nil {
+			return err
+		}
+		if err := fn(ver); err != nil {
+			return err
+		}
+	}
+
+	return cursor.Err()
+}
@@ -0,0 +1,35 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Actions recorded in AuditEntry.Action by every mutating Client method.
+const (
+	AuditActionCreatePackage = "create_package"
+	AuditActionUpdatePackage = "update_package"
+	AuditActionCreateVersion = "create_version"
+	AuditActionYankVersion   = "yank_version"
+	AuditActionUnyankVersion = "unyank_version"
+	AuditActionPurgeVersion  = "purge_version"
+	AuditActionAddFile       = "add_file"
+	AuditActionRemoveFile    = "remove_file"
+)
+
+// AuditEntry is one row of the append-only audit_log collection, written by
+// Client.LogAudit from every mutating Client method and read back through
+// Client.QueryAudit. Before/After capture the affected document (or, for
+// add-file/remove-file, the single models.File) as it looked immediately
+// before and after the change, so a reviewer can reconstruct the diff
+// without consulting anything outside the log itself.
+type AuditEntry struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Actor     string             `bson:"actor" json:"actor"`
+	Action    string             `bson:"action" json:"action"`
+	Target    string             `bson:"target" json:"target"`
+	Before    any                `bson:"before,omitempty" json:"before,omitempty"`
+	After     any                `bson:"after,omitempty" json:"after,omitempty"`
+	CreatedAt time.Time          `bson:"created_at" json:"created_at"`
+}
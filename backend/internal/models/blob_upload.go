@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// BlobUploadSession tracks an in-progress resumable upload, modeled on the
+// OCI distribution spec's blob-upload flow: bytes arrive as a sequence of
+// content-defined chunks appended over one or more PATCH requests, and
+// Finish verifies the reassembled result against a client-supplied SHA-256
+// digest before it's recorded as a file on the version. Sha256State holds
+// the running hash's encoded internal state (via encoding.BinaryMarshaler),
+// so each append only has to read the bytes it's given, never the bytes
+// received by a previous request.
+type BlobUploadSession struct {
+	ID            string             `bson:"_id" json:"id"`
+	PackageID     primitive.ObjectID `bson:"package_id" json:"package_id"`
+	Version       string             `bson:"version" json:"version"`
+	Filename      string             `bson:"filename" json:"filename"`
+	ContentType   string             `bson:"content_type" json:"content_type"`
+	ReceivedBytes int64              `bson:"received_bytes" json:"received_bytes"`
+	ChunkHashes   []string           `bson:"chunk_hashes" json:"-"`
+	Sha256State   []byte             `bson:"sha256_state" json:"-"`
+	CreatedAt     time.Time          `bson:"created_at" json:"created_at"`
+	ExpiresAt     time.Time          `bson:"expires_at" json:"expires_at"`
+}
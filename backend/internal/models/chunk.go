@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// Chunk is a content-addressed block of file data, deduplicated across
+// every package file that happens to contain it. RefCount tracks how many
+// files currently list it in File.Chunks (once per file, however often it
+// repeats there); a chunk is only eligible for garbage collection once it
+// drops to zero.
+type Chunk struct {
+	Hash      string    `bson:"_id" json:"hash"`
+	Size      int64     `bson:"size" json:"size"`
+	RefCount  int64     `bson:"ref_count" json:"ref_count"`
+	CreatedAt time.Time `bson:"created_at" json:"created_at"`
+}
@@ -0,0 +1,40 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Instance is the last known state of a single running client, updated on
+// every check-in. It exists purely for observability and rollout bucketing
+// (hashing InstanceID into a 0-99 bucket), not as an identity or auth record.
+type Instance struct {
+	ID             primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	InstanceID     string             `bson:"instance_id" json:"instance_id"`
+	GroupID        string             `bson:"group_id" json:"group_id"`
+	Channel        string             `bson:"channel" json:"channel"`
+	Platform       string             `bson:"platform" json:"platform"`
+	Arch           string             `bson:"arch" json:"arch"`
+	CurrentVersion string             `bson:"current_version" json:"current_version"`
+	LastSeenIP     string             `bson:"last_seen_ip" json:"last_seen_ip"`
+	LastSeenAt     time.Time          `bson:"last_seen_at" json:"last_seen_at"`
+	CreatedAt      time.Time          `bson:"created_at" json:"created_at"`
+}
+
+// Rollout gates how many checked-in instances of a (Channel, Platform, Arch)
+// are told an update is available, so an operator can promote a build
+// gradually (e.g. 5% -> 25% -> 100%) instead of all-at-once. Percentage is
+// compared against a stable hash of the instance's InstanceID rather than
+// sampled per request, so a given instance doesn't flap in and out of
+// eligibility across check-ins.
+type Rollout struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Channel    string             `bson:"channel" json:"channel"`
+	Platform   string             `bson:"platform" json:"platform"`
+	Arch       string             `bson:"arch" json:"arch"`
+	Percentage int                `bson:"percentage" json:"percentage"`
+	Blacklist  []string           `bson:"blacklist" json:"blacklist"`
+	CreatedAt  time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt  time.Time          `bson:"updated_at" json:"updated_at"`
+}
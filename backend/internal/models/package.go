@@ -18,6 +18,11 @@ type Package struct {
 	Icon        string             `bson:"icon" json:"icon"`
 	CompanyURL  string             `bson:"company_url" json:"company_url"`
 	StarRating  float64            `bson:"star_rating" json:"star_rating"`
+	// Tags holds npm-style dist-tags (e.g. "latest", "beta", "stable")
+	// mapping a tag name to the version string it currently points at, so
+	// clients can pin a channel without knowing its exact version. Managed
+	// through Client.SetTag/RemoveTag rather than UpdatePackage directly.
+	Tags        map[string]string  `bson:"tags,omitempty" json:"tags,omitempty"`
 	CreatedAt   time.Time          `bson:"created_at" json:"created_at"`
 	UpdatedAt   time.Time          `bson:"updated_at" json:"updated_at"`
 }
@@ -31,22 +36,57 @@ type AgentDefinition struct {
 	ClassName   string `bson:"class_name" json:"class_name"`
 }
 
+// Processing states for Version.ProcessingState.
+const (
+	ProcessingStatePending    = "pending"
+	ProcessingStateProcessing = "processing"
+	ProcessingStateReady      = "ready"
+	ProcessingStateFailed     = "failed"
+)
+
 type Version struct {
-	ID           primitive.ObjectID `bson:"_id,omitempty" json:"id"`
-	PackageID    primitive.ObjectID `bson:"package_id" json:"package_id"`
-	Version      string             `bson:"version" json:"version"`
-	Requirements []string           `bson:"requirements" json:"requirements"`
-	Agents       []AgentDefinition  `bson:"agents" json:"agents"`
-	Files        []File             `bson:"files" json:"files"`
-	ReleaseNotes string             `bson:"release_notes" json:"release_notes"`
-	CreatedAt    time.Time          `bson:"created_at" json:"created_at"`
+	ID               primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	PackageID        primitive.ObjectID `bson:"package_id" json:"package_id"`
+	Version          string             `bson:"version" json:"version"`
+	Requirements     []string           `bson:"requirements" json:"requirements"`
+	Agents           []AgentDefinition  `bson:"agents" json:"agents"`
+	Files            []File             `bson:"files" json:"files"`
+	ReleaseNotes     string             `bson:"release_notes" json:"release_notes"`
+	ProcessingState  string             `bson:"processing_state" json:"processing_state"`
+	ProcessingError  string             `bson:"processing_error,omitempty" json:"processing_error,omitempty"`
+	CreatedAt        time.Time          `bson:"created_at" json:"created_at"`
+	// Yanked marks a version as withdrawn without hard-deleting it: it stays
+	// resolvable by its exact version (or download URL), but ListVersions
+	// and ResolveVersion's constraint matching skip it by default, the way
+	// Cargo and npm handle a yank. See Client.YankVersion/UnyankVersion.
+	Yanked     bool       `bson:"yanked,omitempty" json:"yanked,omitempty"`
+	YankedAt   *time.Time `bson:"yanked_at,omitempty" json:"yanked_at,omitempty"`
+	YankedBy   string     `bson:"yanked_by,omitempty" json:"yanked_by,omitempty"`
+	YankReason string     `bson:"yank_reason,omitempty" json:"yank_reason,omitempty"`
 }
 
 type File struct {
-	Name        string    `bson:"name" json:"name"`
-	Size        int64     `bson:"size" json:"size"`
-	Hash        string    `bson:"hash" json:"hash"`
-	ContentType string    `bson:"content_type" json:"content_type"`
-	DownloadURL string    `bson:"download_url" json:"download_url"`
-	UploadedAt  time.Time `bson:"uploaded_at" json:"uploaded_at"`
+	Name        string            `bson:"name" json:"name"`
+	Size        int64             `bson:"size" json:"size"`
+	Hash        string            `bson:"hash" json:"hash"`
+	Hashes      map[string]string `bson:"hashes,omitempty" json:"hashes,omitempty"`
+	// Chunks lists the content-addressed chunk hashes, in order, that
+	// reassemble into this file. Populated by the chunked upload path; a
+	// file uploaded before chunking was added has no Chunks and is stored
+	// as a single blob instead.
+	Chunks      []string       `bson:"chunks,omitempty" json:"chunks,omitempty"`
+	Signature   *FileSignature `bson:"signature,omitempty" json:"signature,omitempty"`
+	ContentType string         `bson:"content_type" json:"content_type"`
+	DownloadURL string         `bson:"download_url" json:"download_url"`
+	UploadedAt  time.Time      `bson:"uploaded_at" json:"uploaded_at"`
+}
+
+// FileSignature is a detached, minisign/cosign-style signature over the
+// canonical `{name}@{version}/{filename}:{sha256}` string for a file,
+// recorded once it has been verified against a trusted publisher key.
+type FileSignature struct {
+	Algorithm string    `bson:"algorithm" json:"algorithm"`
+	KeyID     string    `bson:"key_id" json:"key_id"`
+	Value     string    `bson:"value" json:"value"`
+	SignedAt  time.Time `bson:"signed_at" json:"signed_at"`
 }
@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// ReleaseNotes holds the raw Markdown notes for a single (Channel, Version)
+// release. Notes live in their own collection so the LatestVersion document
+// clients poll on every launch can link to them (NotesURL) instead of
+// embedding a large changelog; Update.Notes also keeps a copy of the
+// Markdown. See handlers.GetReleaseNotes.
+type ReleaseNotes struct {
+	ID        string    `bson:"_id,omitempty" json:"id,omitempty"`
+	Channel   string    `bson:"channel" json:"channel"`
+	Version   string    `bson:"version" json:"version"`
+	Markdown  string    `bson:"markdown" json:"markdown"`
+	CreatedAt time.Time `bson:"created_at" json:"created_at"`
+	UpdatedAt time.Time `bson:"updated_at" json:"updated_at"`
+}
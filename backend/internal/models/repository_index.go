@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// RepositoryIndex caches one generation of a format-native repository index
+// (see internal/registry), keyed by the format it was built for and a
+// digest of the catalog state it was built from, so an unchanged catalog
+// never re-runs a generator. Files holds every output file the generator
+// produced, keyed by its path relative to the format's root (e.g.
+// "repodata/primary.xml.gz").
+type RepositoryIndex struct {
+	ID          string            `bson:"_id" json:"id"`
+	Format      string            `bson:"format" json:"format"`
+	InputHash   string            `bson:"input_hash" json:"input_hash"`
+	Files       map[string][]byte `bson:"files" json:"-"`
+	GeneratedAt time.Time         `bson:"generated_at" json:"generated_at"`
+}
@@ -2,14 +2,32 @@ package models
 
 import "time"
 
-// Update represents an application update
+// Update represents an application update. VersionMajor/Minor/Patch and
+// VersionPrerelease are parsed out of Version once at upload time (see
+// internal/semver) so GetLatestUpdate can sort on numeric fields via a
+// Mongo compound index instead of lexicographically on the raw string,
+// which orders "1.0.0-beta.2" ahead of "1.0.0" and "1.10.0" behind "1.9.0".
 type Update struct {
-	ID          string    `bson:"_id,omitempty" json:"id,omitempty"`
-	Version     string    `bson:"version" json:"version"`
+	ID                string `bson:"_id,omitempty" json:"id,omitempty"`
+	Version           string `bson:"version" json:"version"`
+	VersionMajor      int64  `bson:"version_major" json:"-"`
+	VersionMinor      int64  `bson:"version_minor" json:"-"`
+	VersionPatch      int64  `bson:"version_patch" json:"-"`
+	VersionPrerelease string `bson:"version_prerelease" json:"-"`
+	// VersionIsRelease is true for versions with no prerelease component,
+	// which always rank above any prerelease of the same major.minor.patch.
+	VersionIsRelease bool `bson:"version_is_release" json:"-"`
+	// Channel is the release channel this update was published to (stable,
+	// beta, or nightly). Defaults to "stable" when not specified on upload.
+	Channel     string    `bson:"channel" json:"channel"`
 	Platform    string    `bson:"platform" json:"platform"`
 	Arch        string    `bson:"arch" json:"arch"`
 	Filename    string    `bson:"filename" json:"filename"`
 	FileSize    int64     `bson:"file_size" json:"file_size"`
+	// Sha256 is the hex-encoded digest computed while the file streamed to
+	// storage, so clients can verify the download without a separate pass
+	// over the artifact.
+	Sha256      string    `bson:"sha256" json:"sha256"`
 	Signature   string    `bson:"signature" json:"signature"`
 	ReleaseDate time.Time `bson:"release_date" json:"release_date"`
 	Notes       string    `bson:"notes" json:"notes"`
@@ -23,14 +41,24 @@ type UpdateMetadata struct {
 	Version     string    `json:"version"`
 	Platform    string    `json:"platform"`
 	Arch        string    `json:"arch"`
+	Channel     string    `json:"channel"`
+	Sha256      string    `json:"sha256"`
 	ReleaseDate time.Time `json:"release_date"`
 	Notes       string    `json:"notes"`
 	DownloadURL string    `json:"download_url"`
 }
 
+// LatestVersion is a per-channel snapshot of the newest released update
+// across all platforms; updateLatestVersionDocument keeps one of these
+// documents per Channel so a pre-release channel can't clobber the
+// production "latest" that end-user clients poll.
 type LatestVersion struct {
-	Version   string                           `json:"version"`
-	Notes     string                           `json:"notes"`
+	Channel string `json:"channel"`
+	Version string `json:"version"`
+	// NotesURL points at the notes endpoint for this release (see
+	// handlers.GetLatestReleaseNotes) instead of embedding the Markdown
+	// itself, since this document is polled by clients on every launch.
+	NotesURL  string                           `bson:"-" json:"notes_url,omitempty"`
 	PubDate   string                           `json:"pub_date"`
 	Platforms map[string]LatestVersionPlatform `json:"platforms"`
 }
@@ -38,4 +66,5 @@ type LatestVersion struct {
 type LatestVersionPlatform struct {
 	Signature string `json:"signature"`
 	URL       string `json:"url"`
+	Sha256    string `json:"sha256"`
 }
@@ -14,7 +14,12 @@ type User struct {
 	LastName  string             `bson:"last_name" json:"last_name"`
 	Username  string             `bson:"username" json:"username"`
 	Password  string             `bson:"password" json:"-"`
-	Email     string             `bson:"email" json:"email"`
-	CreatedAt time.Time          `bson:"created_at" json:"created_at"`
-	UpdatedAt time.Time          `bson:"updated_at" json:"updated_at"`
+	// PasswordAlgorithm records which hashing scheme Password was encoded
+	// with. Empty means the legacy bcrypt-default-cost format, predating
+	// this field; it's upgraded to argon2id in place the next time the user
+	// logs in successfully.
+	PasswordAlgorithm string    `bson:"password_algorithm,omitempty" json:"-"`
+	Email             string    `bson:"email" json:"email"`
+	CreatedAt         time.Time `bson:"created_at" json:"created_at"`
+	UpdatedAt         time.Time `bson:"updated_at" json:"updated_at"`
 }
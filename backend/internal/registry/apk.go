@@ -0,0 +1,159 @@
+package registry
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"strings"
+
+	"github.com/Parallels/pd-ai-agents-registry/internal/models"
+)
+
+// ParseAPKSigningKey parses a PEM-encoded RSA private key (PKCS#1 or
+// PKCS#8) as configured via registry.apk_signing_key_pem, for use as
+// Options.APKSigningKey.
+func ParseAPKSigningKey(pemBytes []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(pemBytes)
+	if block == nil {
+		return nil, fmt.Errorf("registry: no PEM block found in apk signing key")
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("registry: failed to parse apk signing key: %w", err)
+	}
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("registry: apk signing key is not an RSA key")
+	}
+	return rsaKey, nil
+}
+
+// apkBuilder generates an Alpine-compatible APKINDEX.tar.gz: a signed tar.gz
+// whose single file, APKINDEX, lists every version of every package as a
+// blank-line-separated record of "key:value" lines. `apk` verifies the
+// signature against the public key it finds at
+// /etc/apk/keys/{APKKeyName}.pub (the name the .SIGN.RSA. entry carries)
+// before trusting the index.
+type apkBuilder struct{}
+
+func (apkBuilder) Build(ctx context.Context, snapshot []PackageSnapshot, baseURL string, opts Options) (map[string][]byte, error) {
+	var index bytes.Buffer
+	for _, pkg := range snapshot {
+		for _, v := range pkg.Versions {
+			writeAPKRecord(&index, pkg.Package, v, baseURL)
+		}
+	}
+
+	content, err := tarGz(map[string][]byte{"APKINDEX": index.Bytes()})
+	if err != nil {
+		return nil, fmt.Errorf("apk: failed to build content tarball: %w", err)
+	}
+
+	signature, err := signAPKIndex(content, opts.APKSigningKey, opts.APKKeyName)
+	if err != nil {
+		return nil, fmt.Errorf("apk: failed to sign index: %w", err)
+	}
+
+	// apk reads APKINDEX.tar.gz as the concatenation of a one-entry
+	// signature tarball followed by the content tarball, each gzipped
+	// independently; it verifies the signature tarball's entry first and
+	// then decodes the remaining bytes as the content tarball.
+	var out bytes.Buffer
+	out.Write(signature)
+	out.Write(content)
+
+	return map[string][]byte{"APKINDEX.tar.gz": out.Bytes()}, nil
+}
+
+// writeAPKRecord appends one APKINDEX record (terminated by a blank line)
+// for a single package version, using the subset of fields apk needs to
+// resolve and fetch a package: checksum, name, version, size, and a couple
+// of descriptive fields. A version with no files yet (still processing) is
+// skipped, since it has nothing for apk to fetch.
+func writeAPKRecord(w *bytes.Buffer, pkg models.Package, v models.Version, baseURL string) {
+	if len(v.Files) == 0 {
+		return
+	}
+	file := v.Files[0]
+
+	fmt.Fprintf(w, "C:%s\n", apkChecksum(file.Hash))
+	fmt.Fprintf(w, "P:%s\n", pkg.Name)
+	fmt.Fprintf(w, "V:%s\n", v.Version)
+	fmt.Fprintf(w, "A:noarch\n")
+	fmt.Fprintf(w, "S:%d\n", file.Size)
+	if pkg.Description != "" {
+		fmt.Fprintf(w, "T:%s\n", strings.ReplaceAll(pkg.Description, "\n", " "))
+	}
+	if pkg.RepoURL != "" {
+		fmt.Fprintf(w, "U:%s\n", pkg.RepoURL)
+	}
+	fmt.Fprintf(w, "o:%s\n", pkg.Name)
+	w.WriteString("\n")
+}
+
+// apkChecksum renders a file hash the way APKINDEX expects it: a "Q1"
+// prefix (apk's marker for "base64 of a raw digest") followed by the
+// base64 encoding of the digest bytes. File.Hash is hex-encoded sha256, so
+// it's decoded back to raw bytes first.
+func apkChecksum(hexHash string) string {
+	raw, err := hex.DecodeString(hexHash)
+	if err != nil {
+		return ""
+	}
+	return "Q1" + base64.StdEncoding.EncodeToString(raw)
+}
+
+// tarGz writes files into a tar archive and gzips it, for both APKINDEX's
+// content tarball and its signature tarball.
+func tarGz(files map[string][]byte) ([]byte, error) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+
+	for name, data := range files {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Size: int64(len(data)), Mode: 0644}); err != nil {
+			return nil, err
+		}
+		if _, err := tw.Write(data); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// signAPKIndex builds the one-entry signature tarball apk expects ahead of
+// the content tarball: a file named ".SIGN.RSA.{keyName}.pub" holding a
+// PKCS#1 v1.5 SHA-256 signature of the content tarball's bytes.
+func signAPKIndex(content []byte, key *rsa.PrivateKey, keyName string) ([]byte, error) {
+	digest := sha256.Sum256(content)
+	sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		return nil, err
+	}
+
+	return tarGz(map[string][]byte{
+		".SIGN.RSA." + keyName + ".pub": sig,
+	})
+}
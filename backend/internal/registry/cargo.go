@@ -0,0 +1,118 @@
+package registry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/Parallels/pd-ai-agents-registry/internal/models"
+)
+
+// cargoBuilder generates a Cargo sparse-registry index: one newline-
+// delimited JSON file per package, at the path `cargo install`/`cargo add`
+// derive from the crate name (see cargoIndexPath), plus the top-level
+// config.json a sparse registry is required to serve. Pointed at with
+// `registries.pd-ai-agents.index = "sparse+{baseURL}/repo/cargo/"`.
+type cargoBuilder struct{}
+
+// cargoCrateVersion is one line of a crate's index file, in the subset of
+// Cargo's per-version schema cargo actually requires to resolve and fetch
+// a dependency.
+type cargoCrateVersion struct {
+	Name     string              `json:"name"`
+	Vers     string              `json:"vers"`
+	Deps     []cargoDependency   `json:"deps"`
+	Cksum    string              `json:"cksum"`
+	Features map[string][]string `json:"features"`
+	Yanked   bool                `json:"yanked"`
+	Links    *string             `json:"links"`
+}
+
+type cargoDependency struct {
+	Name string `json:"name"`
+	Req  string `json:"req"`
+	Kind string `json:"kind"`
+}
+
+func (cargoBuilder) Build(ctx context.Context, snapshot []PackageSnapshot, baseURL string, opts Options) (map[string][]byte, error) {
+	files := make(map[string][]byte, len(snapshot)+1)
+
+	for _, pkg := range snapshot {
+		data, err := buildCargoCrateIndex(pkg.Package, pkg.Versions)
+		if err != nil {
+			return nil, fmt.Errorf("cargo: failed to build index for %s: %w", pkg.Package.Name, err)
+		}
+		if data == nil {
+			continue
+		}
+		files[cargoIndexPath(pkg.Package.Name)] = data
+	}
+
+	config, err := json.Marshal(map[string]string{
+		"dl":  baseURL + "/api/v1/download/{crate}/{version}/{crate}-{version}.crate",
+		"api": baseURL,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("cargo: failed to build config.json: %w", err)
+	}
+	files["config.json"] = config
+
+	return files, nil
+}
+
+// buildCargoCrateIndex renders every published version of one crate as a
+// sequence of JSON lines, oldest first, the format cargo's sparse
+// registry client streams and parses one line at a time.
+func buildCargoCrateIndex(pkg models.Package, versions []models.Version) ([]byte, error) {
+	var lines bytes.Buffer
+	wrote := false
+
+	for _, v := range versions {
+		if len(v.Files) == 0 {
+			continue
+		}
+
+		entry := cargoCrateVersion{
+			Name:     pkg.Name,
+			Vers:     v.Version,
+			Deps:     []cargoDependency{},
+			Cksum:    v.Files[0].Hash,
+			Features: map[string][]string{},
+			Yanked:   false,
+		}
+		line, err := json.Marshal(entry)
+		if err != nil {
+			return nil, err
+		}
+		lines.Write(line)
+		lines.WriteByte('\n')
+		wrote = true
+	}
+
+	if !wrote {
+		return nil, nil
+	}
+	return lines.Bytes(), nil
+}
+
+// cargoIndexPath implements Cargo's sparse-index layout rule: a 1- or
+// 2-character name is stored directly under a directory named for its
+// length, a 3-character name gets an extra directory level keyed by its
+// first character, and everything else nests under its first two and next
+// two characters (lowercased, since cargo registry paths are
+// case-insensitive by convention).
+func cargoIndexPath(name string) string {
+	lower := strings.ToLower(name)
+	switch len(lower) {
+	case 1:
+		return fmt.Sprintf("1/%s", lower)
+	case 2:
+		return fmt.Sprintf("2/%s", lower)
+	case 3:
+		return fmt.Sprintf("3/%c/%s", lower[0], lower)
+	default:
+		return fmt.Sprintf("%s/%s/%s", lower[0:2], lower[2:4], lower)
+	}
+}
@@ -0,0 +1,101 @@
+package registry
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"fmt"
+	"strings"
+
+	"github.com/Parallels/pd-ai-agents-registry/internal/models"
+)
+
+// debBuilder generates a Debian-compatible "Packages"/"Packages.gz" index
+// plus the "Release" file apt uses to discover and checksum it, so an agent
+// package can be installed with `apt-get install` once the registry is
+// added as an apt source (`deb [trusted=yes] {baseURL}/repo/deb ./`).
+type debBuilder struct{}
+
+func (debBuilder) Build(ctx context.Context, snapshot []PackageSnapshot, baseURL string, opts Options) (map[string][]byte, error) {
+	var packages bytes.Buffer
+	for _, pkg := range snapshot {
+		for _, v := range pkg.Versions {
+			writeDebStanza(&packages, pkg.Package, v, baseURL)
+		}
+	}
+
+	var gzipped bytes.Buffer
+	gz := gzip.NewWriter(&gzipped)
+	if _, err := gz.Write(packages.Bytes()); err != nil {
+		return nil, fmt.Errorf("deb: failed to gzip Packages: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return nil, fmt.Errorf("deb: failed to gzip Packages: %w", err)
+	}
+
+	release := buildDebRelease(map[string][]byte{
+		"Packages":    packages.Bytes(),
+		"Packages.gz": gzipped.Bytes(),
+	})
+
+	return map[string][]byte{
+		"Packages":    packages.Bytes(),
+		"Packages.gz": gzipped.Bytes(),
+		"Release":     release,
+	}, nil
+}
+
+// writeDebStanza appends one Packages stanza (terminated by a blank line)
+// for a single package version. Architecture is always "all": agent
+// packages aren't compiled, so there's nothing architecture-specific to
+// publish separately.
+func writeDebStanza(w *bytes.Buffer, pkg models.Package, v models.Version, baseURL string) {
+	if len(v.Files) == 0 {
+		return
+	}
+	file := v.Files[0]
+
+	fmt.Fprintf(w, "Package: %s\n", pkg.Name)
+	fmt.Fprintf(w, "Version: %s\n", v.Version)
+	fmt.Fprintf(w, "Architecture: all\n")
+	if pkg.Author != "" {
+		fmt.Fprintf(w, "Maintainer: %s\n", pkg.Author)
+	}
+	fmt.Fprintf(w, "Filename: pool/%s_%s.deb\n", pkg.Name, v.Version)
+	fmt.Fprintf(w, "Size: %d\n", file.Size)
+	fmt.Fprintf(w, "SHA256: %s\n", file.Hash)
+	description := pkg.Description
+	if description == "" {
+		description = pkg.Name
+	}
+	fmt.Fprintf(w, "Description: %s\n", strings.ReplaceAll(description, "\n", " "))
+	w.WriteString("\n")
+}
+
+// buildDebRelease renders the Release file apt fetches first, which points
+// it at Packages/Packages.gz and pins their size and MD5Sum/SHA256 so a
+// tampered or truncated fetch is rejected before apt trusts its contents.
+func buildDebRelease(files map[string][]byte) []byte {
+	var b strings.Builder
+	b.WriteString("Origin: pd-ai-agents-registry\n")
+	b.WriteString("Label: pd-ai-agents-registry\n")
+	b.WriteString("Suite: stable\n")
+	b.WriteString("Codename: stable\n")
+	b.WriteString("Architectures: all\n")
+	b.WriteString("Components: main\n")
+
+	b.WriteString("MD5Sum:\n")
+	for _, name := range []string{"Packages", "Packages.gz"} {
+		sum := md5.Sum(files[name])
+		fmt.Fprintf(&b, " %x %d %s\n", sum, len(files[name]), name)
+	}
+	b.WriteString("SHA256:\n")
+	for _, name := range []string{"Packages", "Packages.gz"} {
+		sum := sha256.Sum256(files[name])
+		fmt.Fprintf(&b, " %x %d %s\n", sum, len(files[name]), name)
+	}
+
+	return []byte(b.String())
+}
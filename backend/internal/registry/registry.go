@@ -0,0 +1,152 @@
+// Package registry turns the generic packages/versions/files stored via
+// internal/db/mongodb into the format-native repository indexes that
+// existing package managers (apk, apt, dnf, cargo) know how to consume
+// directly, so the registry can be pointed at by their native client
+// tooling instead of only its own JSON API.
+package registry
+
+import (
+	"context"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/Parallels/pd-ai-agents-registry/internal/db/mongodb"
+	"github.com/Parallels/pd-ai-agents-registry/internal/models"
+)
+
+// PackageSnapshot is one package and its versions, as assembled by Snapshot
+// for a RepositoryBuilder to consume.
+type PackageSnapshot struct {
+	Package  models.Package
+	Versions []models.Version
+}
+
+// RepositoryBuilder generates a format-native repository index from a
+// snapshot of the catalog. Build returns every output file the format
+// needs, keyed by its path relative to the format's /repo/{format}/ root
+// (e.g. "repodata/primary.xml.gz" for rpm, "pd/-c/pd-cli" for cargo). opts
+// carries the settings a builder needs beyond the catalog itself (just the
+// apk signing key, today); builders that don't need any of it ignore opts.
+type RepositoryBuilder interface {
+	Build(ctx context.Context, snapshot []PackageSnapshot, baseURL string, opts Options) (map[string][]byte, error)
+}
+
+// Options carries per-format settings Generate can't derive from the
+// catalog snapshot alone.
+type Options struct {
+	// APKSigningKey signs APKINDEX.tar.gz the way `abuild-sign` signs a
+	// real Alpine repository. The apk format is skipped (SupportedFormat
+	// still reports it, but Generate fails) when this is nil, since apk
+	// rejects an unsigned index by default.
+	APKSigningKey *rsa.PrivateKey
+	APKKeyName    string
+}
+
+// builders lists every supported /repo/{format}/... generator.
+var builders = map[string]RepositoryBuilder{
+	"apk":   apkBuilder{},
+	"deb":   debBuilder{},
+	"rpm":   rpmBuilder{},
+	"cargo": cargoBuilder{},
+}
+
+// SupportedFormat reports whether format names a registered builder.
+func SupportedFormat(format string) bool {
+	_, ok := builders[format]
+	return ok
+}
+
+// Generate returns format's current index, reusing a cached build (see
+// mongodb.Client.GetCachedIndex) when the catalog hasn't changed since it
+// was produced.
+func Generate(ctx context.Context, db *mongodb.Client, format, baseURL string, opts Options) (map[string][]byte, error) {
+	builder, ok := builders[format]
+	if !ok {
+		return nil, fmt.Errorf("registry: unknown format %q", format)
+	}
+	if format == "apk" && opts.APKSigningKey == nil {
+		return nil, fmt.Errorf("registry: apk format requires registry.apk_signing_key_pem to be configured")
+	}
+
+	snapshot, inputHash, err := Snapshot(ctx, db)
+	if err != nil {
+		return nil, fmt.Errorf("registry: failed to snapshot catalog: %w", err)
+	}
+
+	cached, err := db.GetCachedIndex(ctx, format, inputHash)
+	if err != nil {
+		return nil, fmt.Errorf("registry: failed to read cached index: %w", err)
+	}
+	if cached != nil {
+		return cached.Files, nil
+	}
+
+	files, err := builder.Build(ctx, snapshot, baseURL, opts)
+	if err != nil {
+		return nil, fmt.Errorf("registry: failed to build %s index: %w", format, err)
+	}
+
+	if err := db.PutCachedIndex(ctx, format, inputHash, files); err != nil {
+		return nil, fmt.Errorf("registry: failed to cache %s index: %w", format, err)
+	}
+
+	return files, nil
+}
+
+// Snapshot streams the full package catalog through
+// mongodb.Client.StreamPackages/StreamVersions and returns it alongside a
+// digest of the catalog state (every package's id and update time and every
+// version's id and creation time), so Generate can tell a previous build apart from a
+// stale one without diffing the built files themselves.
+func Snapshot(ctx context.Context, db *mongodb.Client) ([]PackageSnapshot, string, error) {
+	var snapshots []PackageSnapshot
+
+	err := db.StreamPackages(ctx, func(pkg models.Package) error {
+		var versions []models.Version
+		if err := db.StreamVersions(ctx, pkg.ID, func(ver models.Version) error {
+			// Yanked versions stay resolvable by exact download but drop out
+			// of generated indexes, the same way they drop out of
+			// ListVersions' default results.
+			if ver.Yanked {
+				return nil
+			}
+			versions = append(versions, ver)
+			return nil
+		}); err != nil {
+			return err
+		}
+
+		sort.Slice(versions, func(i, j int) bool { return versions[i].ID.Hex() < versions[j].ID.Hex() })
+		snapshots = append(snapshots, PackageSnapshot{Package: pkg, Versions: versions})
+		return nil
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	sort.Slice(snapshots, func(i, j int) bool { return snapshots[i].Package.ID.Hex() < snapshots[j].Package.ID.Hex() })
+
+	return snapshots, inputHash(snapshots), nil
+}
+
+// inputHash digests the parts of the catalog a generator's output depends
+// on, in a fixed (sorted) order so the same catalog state always hashes the
+// same way regardless of the order Mongo happened to stream it in.
+func inputHash(snapshots []PackageSnapshot) string {
+	hasher := sha256.New()
+	for _, s := range snapshots {
+		fmt.Fprintf(hasher, "pkg:%s:%s\n", s.Package.ID.Hex(), formatTime(s.Package.UpdatedAt))
+		for _, v := range s.Versions {
+			fmt.Fprintf(hasher, "ver:%s:%s\n", v.ID.Hex(), formatTime(v.CreatedAt))
+		}
+	}
+	return hex.EncodeToString(hasher.Sum(nil))
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339Nano)
+}
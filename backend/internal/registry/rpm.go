@@ -0,0 +1,171 @@
+package registry
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/xml"
+	"fmt"
+	"html"
+	"time"
+
+	"github.com/Parallels/pd-ai-agents-registry/internal/models"
+)
+
+// rpmBuilder generates a `dnf`/`yum`-compatible repodata/ directory:
+// repomd.xml pointing at gzipped primary/filelists/other metadata, so an
+// agent package can be installed with `dnf install` once the registry is
+// added as a repo (`baseurl={baseURL}/repo/rpm`).
+type rpmBuilder struct{}
+
+func (rpmBuilder) Build(ctx context.Context, snapshot []PackageSnapshot, baseURL string, opts Options) (map[string][]byte, error) {
+	var packageCount int
+	for _, pkg := range snapshot {
+		for _, v := range pkg.Versions {
+			if len(v.Files) > 0 {
+				packageCount++
+			}
+		}
+	}
+
+	primary, err := gzipXML(buildPrimaryXML(snapshot, packageCount))
+	if err != nil {
+		return nil, fmt.Errorf("rpm: failed to build primary.xml.gz: %w", err)
+	}
+	filelists, err := gzipXML(buildFilelistsXML(snapshot, packageCount))
+	if err != nil {
+		return nil, fmt.Errorf("rpm: failed to build filelists.xml.gz: %w", err)
+	}
+	other, err := gzipXML(buildOtherXML(snapshot, packageCount))
+	if err != nil {
+		return nil, fmt.Errorf("rpm: failed to build other.xml.gz: %w", err)
+	}
+
+	repomd := buildRepomdXML(map[string][]byte{
+		"primary":   primary,
+		"filelists": filelists,
+		"other":     other,
+	})
+
+	return map[string][]byte{
+		"repodata/repomd.xml":       repomd,
+		"repodata/primary.xml.gz":   primary,
+		"repodata/filelists.xml.gz": filelists,
+		"repodata/other.xml.gz":     other,
+	}, nil
+}
+
+func gzipXML(content []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	if _, err := gz.Write(content); err != nil {
+		return nil, err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// buildPrimaryXML renders the metadata dnf resolves package installs
+// against: name, version, checksum, size, and the download location of
+// each version's file.
+func buildPrimaryXML(snapshot []PackageSnapshot, packageCount int) []byte {
+	var b bytes.Buffer
+	b.WriteString(xml.Header)
+	fmt.Fprintf(&b, "<metadata xmlns=\"http://linux.duke.edu/metadata/common\" xmlns:rpm=\"http://linux.duke.edu/metadata/rpm\" packages=\"%d\">\n", packageCount)
+
+	for _, pkg := range snapshot {
+		for _, v := range pkg.Versions {
+			if len(v.Files) == 0 {
+				continue
+			}
+			file := v.Files[0]
+
+			fmt.Fprintf(&b, "  <package type=\"rpm\">\n")
+			fmt.Fprintf(&b, "    <name>%s</name>\n", html.EscapeString(pkg.Package.Name))
+			fmt.Fprintf(&b, "    <arch>noarch</arch>\n")
+			fmt.Fprintf(&b, "    <version epoch=\"0\" ver=\"%s\" rel=\"1\"/>\n", html.EscapeString(v.Version))
+			fmt.Fprintf(&b, "    <checksum type=\"sha256\" pkgid=\"YES\">%s</checksum>\n", file.Hash)
+			fmt.Fprintf(&b, "    <summary>%s</summary>\n", html.EscapeString(pkg.Package.Description))
+			fmt.Fprintf(&b, "    <description>%s</description>\n", html.EscapeString(pkg.Package.Description))
+			fmt.Fprintf(&b, "    <packager>%s</packager>\n", html.EscapeString(pkg.Package.Author))
+			fmt.Fprintf(&b, "    <url>%s</url>\n", html.EscapeString(pkg.Package.RepoURL))
+			fmt.Fprintf(&b, "    <time file=\"%d\" build=\"%d\"/>\n", v.CreatedAt.Unix(), v.CreatedAt.Unix())
+			fmt.Fprintf(&b, "    <size package=\"%d\" installed=\"%d\" archive=\"%d\"/>\n", file.Size, file.Size, file.Size)
+			fmt.Fprintf(&b, "    <location href=\"%s-%s.rpm\"/>\n", pkg.Package.Name, v.Version)
+			b.WriteString("    <format/>\n")
+			b.WriteString("  </package>\n")
+		}
+	}
+
+	b.WriteString("</metadata>\n")
+	return b.Bytes()
+}
+
+// buildFilelistsXML and buildOtherXML round out the three metadata files
+// every dnf/yum repo ships; the registry doesn't track individual file
+// paths or changelogs within a package archive, so each package entry here
+// is an empty placeholder that still gives dnf a pkgid to cross-reference
+// against primary.xml.
+func buildFilelistsXML(snapshot []PackageSnapshot, packageCount int) []byte {
+	var b bytes.Buffer
+	b.WriteString(xml.Header)
+	fmt.Fprintf(&b, "<filelists xmlns=\"http://linux.duke.edu/metadata/filelists\" packages=\"%d\">\n", packageCount)
+	forEachPublishedVersion(snapshot, func(pkg models.Package, v models.Version, file models.File) {
+		fmt.Fprintf(&b, "  <package pkgid=\"%s\" name=\"%s\" arch=\"noarch\">\n", file.Hash, html.EscapeString(pkg.Name))
+		fmt.Fprintf(&b, "    <version epoch=\"0\" ver=\"%s\" rel=\"1\"/>\n", html.EscapeString(v.Version))
+		b.WriteString("  </package>\n")
+	})
+	b.WriteString("</filelists>\n")
+	return b.Bytes()
+}
+
+func buildOtherXML(snapshot []PackageSnapshot, packageCount int) []byte {
+	var b bytes.Buffer
+	b.WriteString(xml.Header)
+	fmt.Fprintf(&b, "<otherdata xmlns=\"http://linux.duke.edu/metadata/other\" packages=\"%d\">\n", packageCount)
+	forEachPublishedVersion(snapshot, func(pkg models.Package, v models.Version, file models.File) {
+		fmt.Fprintf(&b, "  <package pkgid=\"%s\" name=\"%s\" arch=\"noarch\">\n", file.Hash, html.EscapeString(pkg.Name))
+		fmt.Fprintf(&b, "    <version epoch=\"0\" ver=\"%s\" rel=\"1\"/>\n", html.EscapeString(v.Version))
+		b.WriteString("  </package>\n")
+	})
+	b.WriteString("</otherdata>\n")
+	return b.Bytes()
+}
+
+func forEachPublishedVersion(snapshot []PackageSnapshot, fn func(models.Package, models.Version, models.File)) {
+	for _, pkg := range snapshot {
+		for _, v := range pkg.Versions {
+			if len(v.Files) == 0 {
+				continue
+			}
+			fn(pkg.Package, v, v.Files[0])
+		}
+	}
+}
+
+// buildRepomdXML renders the entry point `dnf` fetches first: one <data>
+// element per metadata file, each pinned to its own sha256 checksum and
+// size so a tampered or truncated fetch is rejected before dnf trusts it.
+func buildRepomdXML(files map[string][]byte) []byte {
+	var b bytes.Buffer
+	b.WriteString(xml.Header)
+	b.WriteString("<repomd xmlns=\"http://linux.duke.edu/metadata/repo\">\n")
+	fmt.Fprintf(&b, "  <revision>%d</revision>\n", time.Now().Unix())
+
+	for _, name := range []string{"primary", "filelists", "other"} {
+		data := files[name]
+		sum := sha256.Sum256(data)
+		fmt.Fprintf(&b, "  <data type=\"%s\">\n", name)
+		fmt.Fprintf(&b, "    <checksum type=\"sha256\">%x</checksum>\n", sum)
+		fmt.Fprintf(&b, "    <location href=\"repodata/%s.xml.gz\"/>\n", name)
+		fmt.Fprintf(&b, "    <timestamp>%d</timestamp>\n", time.Now().Unix())
+		fmt.Fprintf(&b, "    <size>%d</size>\n", len(data))
+		b.WriteString("  </data>\n")
+	}
+
+	b.WriteString("</repomd>\n")
+	return b.Bytes()
+}
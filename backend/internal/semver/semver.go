@@ -0,0 +1,91 @@
+// Package semver parses and compares SemVer 2.0.0 version strings, backed
+// by Masterminds/semver. Callers that used to split on "." and compare
+// integer-by-integer should parse once with Parse and persist the
+// resulting components, since prerelease precedence (rc.1 < rc.2 < "",
+// numeric identifiers compared numerically, alphanumeric ones
+// lexicographically) can't be recovered from a naive dotted-string sort.
+package semver
+
+import (
+	"fmt"
+
+	mastermindsemver "github.com/Masterminds/semver/v3"
+)
+
+// Version is the parsed form of a SemVer 2.0.0 string, with the components
+// that matter for sorting and display pulled out so callers can persist
+// them (e.g. as separate Mongo fields) instead of re-parsing on every read.
+type Version struct {
+	Major      int64
+	Minor      int64
+	Patch      int64
+	Prerelease string
+	Metadata   string
+	Original   string
+
+	parsed *mastermindsemver.Version
+}
+
+// Parse validates raw as a SemVer 2.0.0 version (an optional leading "v" is
+// accepted and stripped) and returns its components.
+func Parse(raw string) (*Version, error) {
+	v, err := mastermindsemver.NewVersion(raw)
+	if err != nil {
+		return nil, fmt.Errorf("semver: %q is not a valid version: %w", raw, err)
+	}
+	return &Version{
+		Major:      int64(v.Major()),
+		Minor:      int64(v.Minor()),
+		Patch:      int64(v.Patch()),
+		Prerelease: v.Prerelease(),
+		Metadata:   v.Metadata(),
+		Original:   v.Original(),
+		parsed:     v,
+	}, nil
+}
+
+// Compare returns 1 if v > other, -1 if v < other, and 0 if they're equal
+// under SemVer 2.0.0 precedence: major.minor.patch compare numerically,
+// and a version with a prerelease is lower than the same major.minor.patch
+// without one; prereleases are then compared identifier by identifier,
+// numeric identifiers numerically and alphanumeric ones lexicographically.
+// Build metadata never affects precedence.
+func (v *Version) Compare(other *Version) int {
+	return v.parsed.Compare(other.parsed)
+}
+
+// Compare parses a and b as SemVer 2.0.0 versions and returns 1 if a > b,
+// -1 if a < b, and 0 if they're equal. It returns an error if either string
+// fails to parse.
+func Compare(a, b string) (int, error) {
+	va, err := Parse(a)
+	if err != nil {
+		return 0, err
+	}
+	vb, err := Parse(b)
+	if err != nil {
+		return 0, err
+	}
+	return va.Compare(vb), nil
+}
+
+// Constraint is a parsed semver range expression, e.g. "^1.2.0" or
+// ">=2.0.0 <3.0.0".
+type Constraint struct {
+	parsed *mastermindsemver.Constraints
+}
+
+// ParseConstraint validates raw as a semver range expression and returns it,
+// for resolving a version spec like "^1.2.0" against a package's versions.
+func ParseConstraint(raw string) (*Constraint, error) {
+	c, err := mastermindsemver.NewConstraint(raw)
+	if err != nil {
+		return nil, fmt.Errorf("semver: %q is not a valid constraint: %w", raw, err)
+	}
+	return &Constraint{parsed: c}, nil
+}
+
+// Satisfies reports whether v falls within the constraint's range.
+func (c *Constraint) Satisfies(v *Version) bool {
+	return c.parsed.Check(v.parsed)
+}
@@ -0,0 +1,76 @@
+// Package signing verifies the detached, ed25519-based package signatures
+// used by the sign endpoint and the async upload-processing pipeline. Keys
+// are hex-encoded ed25519 public keys, keyed by an opaque key ID, and are
+// loaded from the registry's trusted-key configuration.
+package signing
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"fmt"
+)
+
+// AlgorithmEd25519 is the only signature algorithm the registry trusts.
+const AlgorithmEd25519 = "ed25519"
+
+// TrustedKey is a single publisher public key accepted by the registry.
+type TrustedKey struct {
+	KeyID     string `json:"key_id"`
+	Algorithm string `json:"algorithm"`
+	PublicKey string `json:"public_key"`
+}
+
+// Verifier holds the set of trusted publisher public keys and checks
+// detached signatures against them.
+type Verifier struct {
+	keys map[string]ed25519.PublicKey
+}
+
+// NewVerifier builds a Verifier from a map of key ID to hex-encoded ed25519
+// public key, as loaded from configuration.
+func NewVerifier(trustedKeys map[string]string) (*Verifier, error) {
+	keys := make(map[string]ed25519.PublicKey, len(trustedKeys))
+	for keyID, hexKey := range trustedKeys {
+		raw, err := hex.DecodeString(hexKey)
+		if err != nil {
+			return nil, fmt.Errorf("signing: trusted key %q is not valid hex: %w", keyID, err)
+		}
+		if len(raw) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("signing: trusted key %q has invalid length %d", keyID, len(raw))
+		}
+		keys[keyID] = ed25519.PublicKey(raw)
+	}
+	return &Verifier{keys: keys}, nil
+}
+
+// CanonicalMessage builds the exact byte string that package signatures are
+// computed over: `{name}@{version}/{filename}:{sha256}`.
+func CanonicalMessage(name, version, filename, sha256Hex string) []byte {
+	return []byte(fmt.Sprintf("%s@%s/%s:%s", name, version, filename, sha256Hex))
+}
+
+// Verify reports whether signature is a valid ed25519 signature of message
+// under the trusted key identified by keyID. It returns false, rather than
+// an error, for an unknown key ID so callers can treat "untrusted" and
+// "invalid" identically.
+func (v *Verifier) Verify(keyID string, message, signature []byte) bool {
+	key, ok := v.keys[keyID]
+	if !ok {
+		return false
+	}
+	return ed25519.Verify(key, message, signature)
+}
+
+// TrustedKeys returns every currently trusted publisher key, for the public
+// trusted-keys.json endpoint.
+func (v *Verifier) TrustedKeys() []TrustedKey {
+	out := make([]TrustedKey, 0, len(v.keys))
+	for keyID, key := range v.keys {
+		out = append(out, TrustedKey{
+			KeyID:     keyID,
+			Algorithm: AlgorithmEd25519,
+			PublicKey: hex.EncodeToString(key),
+		})
+	}
+	return out
+}
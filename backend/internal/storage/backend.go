@@ -0,0 +1,121 @@
+// Package storage defines a pluggable object-storage abstraction used to
+// persist uploaded package and update artifacts, independent of where the
+// bytes actually live (local disk, S3, MinIO, ...).
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/Parallels/pd-ai-agents-registry/internal/config"
+)
+
+// ErrPresignNotSupported is returned by backends that cannot generate
+// presigned URLs (e.g. the filesystem backend). Callers should fall back to
+// streaming the object through Get instead of redirecting.
+var ErrPresignNotSupported = errors.New("storage: backend does not support presigned URLs")
+
+// ErrMultipartNotSupported reports that a backend has no concept of a
+// multipart upload, i.e. does not implement MultipartBackend. Callers should
+// fall back to the single-request upload path instead.
+var ErrMultipartNotSupported = errors.New("storage: backend does not support multipart uploads")
+
+// CompletedPart identifies one previously-uploaded part by its number and
+// the ETag the backend returned for it, as required to finalize a
+// multipart upload.
+type CompletedPart struct {
+	PartNumber int32
+	ETag       string
+}
+
+// MultipartBackend is implemented by backends that can hand multipart
+// upload control directly to the client, so large files never pass through
+// the API server. Not every Backend implements it; callers type-assert for
+// it and treat its absence as multipart being unsupported.
+type MultipartBackend interface {
+	// CreateMultipartUpload starts a new multipart upload for key and
+	// returns the upload ID the client references for every subsequent
+	// part and the completion call.
+	CreateMultipartUpload(ctx context.Context, key, contentType string) (uploadID string, err error)
+	// PresignUploadPart returns a time-limited URL the client PUTs a single
+	// part's bytes to directly.
+	PresignUploadPart(ctx context.Context, key, uploadID string, partNumber int32, ttl time.Duration) (string, error)
+	// CompleteMultipartUpload finalizes the upload once every part has been
+	// PUT, and returns the resulting object's download URL and ETag.
+	CompleteMultipartUpload(ctx context.Context, key, uploadID string, parts []CompletedPart) (url string, etag string, err error)
+	// AbortMultipartUpload discards an in-progress multipart upload and
+	// frees any parts already stored for it.
+	AbortMultipartUpload(ctx context.Context, key, uploadID string) error
+}
+
+// IntegrityTagger is implemented by backends that record a content hash as
+// storage-native object metadata (currently just S3, via object tags), so
+// callers can check it independently of whatever's recorded in MongoDB.
+// Not every Backend implements it; callers type-assert for it and skip the
+// extra check otherwise.
+type IntegrityTagger interface {
+	// GetIntegrityTag returns the sha256 digest stored for key, or "" if the
+	// object carries none.
+	GetIntegrityTag(ctx context.Context, key string) (string, error)
+}
+
+// Backend is the storage abstraction implemented by each concrete driver.
+type Backend interface {
+	// Put streams r to the object identified by key and returns the
+	// resulting public/download URL and ETag (when the backend provides one).
+	Put(ctx context.Context, key string, r io.Reader, contentType string) (url string, etag string, err error)
+	// Get opens the object identified by key for reading. The caller owns
+	// the returned reader and must Close it.
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete removes the object identified by key.
+	Delete(ctx context.Context, key string) error
+	// Exists reports whether an object identified by key is present.
+	Exists(ctx context.Context, key string) (bool, error)
+	// Size returns the size in bytes of the object identified by key.
+	Size(ctx context.Context, key string) (int64, error)
+	// PresignGet returns a time-limited URL that can be used to download the
+	// object directly, bypassing the API server. Returns
+	// ErrPresignNotSupported if the backend has no concept of presigning.
+	PresignGet(ctx context.Context, key string, ttl time.Duration) (string, error)
+}
+
+// New builds the configured storage backend, wrapping it for client-side
+// encryption when cfg.Encryption.Mode requests it. sse-s3/sse-kms are
+// handled inside NewS3Backend instead, since they're native S3 behavior
+// rather than something every driver needs to support.
+func New(cfg config.StorageConfig) (Backend, error) {
+	backend, err := newBackend(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.Encryption.Mode == "client-side" {
+		wrapper, err := NewKeyWrapper(cfg.Encryption)
+		if err != nil {
+			return nil, fmt.Errorf("storage: failed to configure client-side encryption: %w", err)
+		}
+		backend = NewEncryptingBackend(backend, wrapper)
+	}
+
+	return backend, nil
+}
+
+func newBackend(cfg config.StorageConfig) (Backend, error) {
+	switch cfg.Type {
+	case "", "s3":
+		return NewS3Backend(cfg.S3, cfg.Encryption)
+	case "filesystem":
+		return NewFilesystemBackend(cfg.Filesystem)
+	case "minio":
+		return NewMinIOBackend(cfg.MinIO)
+	case "gcs":
+		return NewGCSBackend(cfg.GCS)
+	case "azure":
+		return NewAzureBackend(cfg.Azure)
+	default:
+		return nil, errors.New("storage: unknown backend type " + cfg.Type)
+	}
+}
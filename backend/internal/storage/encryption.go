@@ -0,0 +1,310 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	gcpkms "cloud.google.com/go/kms/apiv1"
+	gcpkmspb "cloud.google.com/go/kms/apiv1/kmspb"
+	"github.com/Parallels/pd-ai-agents-registry/internal/config"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+)
+
+// encryptionHeaderMagic tags the front of an encrypted object so a future
+// reader (or a migration that flips kms_key_id) can tell an object apart
+// from one written before encryption was enabled, rather than trying to
+// AES-GCM-open plaintext and failing with a confusing error.
+const encryptionHeaderMagic = "PDAIENC1"
+
+// KeyWrapper wraps and unwraps the per-object data encryption key (DEK)
+// EncryptingBackend generates for every Put. Only the wrapped DEK is stored
+// alongside the object; the master key backing WrapKey/UnwrapKey never
+// leaves the KMS (or, for the local provider, is held only in memory).
+type KeyWrapper interface {
+	WrapKey(ctx context.Context, dek []byte) ([]byte, error)
+	UnwrapKey(ctx context.Context, wrapped []byte) ([]byte, error)
+}
+
+// NewKeyWrapper builds the KeyWrapper selected by cfg.KMSProvider. It is
+// only called when cfg.Mode == "client-side"; sse-s3/sse-kms delegate
+// encryption to the backend itself and never need a KeyWrapper.
+func NewKeyWrapper(cfg config.EncryptionConfig) (KeyWrapper, error) {
+	switch cfg.KMSProvider {
+	case "", "local":
+		return newLocalKeyWrapper(cfg.LocalMasterKey)
+	case "aws":
+		return newAWSKMSKeyWrapper(cfg.KMSKeyID)
+	case "gcp":
+		return newGCPKMSKeyWrapper(cfg.KMSKeyID)
+	default:
+		return nil, fmt.Errorf("storage: unknown kms_provider %q", cfg.KMSProvider)
+	}
+}
+
+// localKeyWrapper wraps data keys with a static AES-256-GCM master key held
+// in memory, for deployments without access to a cloud KMS.
+type localKeyWrapper struct {
+	master cipher.AEAD
+}
+
+func newLocalKeyWrapper(base64Key string) (*localKeyWrapper, error) {
+	if base64Key == "" {
+		return nil, errors.New("storage: storage.encryption.local_master_key is required when kms_provider is \"local\"")
+	}
+	key, err := base64.StdEncoding.DecodeString(base64Key)
+	if err != nil {
+		return nil, fmt.Errorf("storage: local_master_key is not valid base64: %w", err)
+	}
+	if len(key) != 32 {
+		return nil, fmt.Errorf("storage: local_master_key must decode to 32 bytes (AES-256), got %d", len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("storage: failed to build local master cipher: %w", err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("storage: failed to build local master AEAD: %w", err)
+	}
+	return &localKeyWrapper{master: gcm}, nil
+}
+
+func (w *localKeyWrapper) WrapKey(_ context.Context, dek []byte) ([]byte, error) {
+	nonce := make([]byte, w.master.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("storage: failed to generate key-wrap nonce: %w", err)
+	}
+	return w.master.Seal(nonce, nonce, dek, nil), nil
+}
+
+func (w *localKeyWrapper) UnwrapKey(_ context.Context, wrapped []byte) ([]byte, error) {
+	if len(wrapped) < w.master.NonceSize() {
+		return nil, errors.New("storage: wrapped key is too short")
+	}
+	nonce, ciphertext := wrapped[:w.master.NonceSize()], wrapped[w.master.NonceSize():]
+	dek, err := w.master.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, fmt.Errorf("storage: failed to unwrap data key: %w", err)
+	}
+	return dek, nil
+}
+
+// awsKMSKeyWrapper wraps data keys with an AWS KMS customer master key via
+// Encrypt/Decrypt, matching how cfg.KMSKeyID is used elsewhere as a key ARN
+// or alias.
+type awsKMSKeyWrapper struct {
+	client *kms.Client
+	keyID  string
+}
+
+func newAWSKMSKeyWrapper(keyID string) (*awsKMSKeyWrapper, error) {
+	if keyID == "" {
+		return nil, errors.New("storage: storage.encryption.kms_key_id is required when kms_provider is \"aws\"")
+	}
+	awsCfg, err := awsconfig.LoadDefaultConfig(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("storage: failed to load AWS config: %w", err)
+	}
+	return &awsKMSKeyWrapper{client: kms.NewFromConfig(awsCfg), keyID: keyID}, nil
+}
+
+func (w *awsKMSKeyWrapper) WrapKey(ctx context.Context, dek []byte) ([]byte, error) {
+	out, err := w.client.Encrypt(ctx, &kms.EncryptInput{
+		KeyId:     &w.keyID,
+		Plaintext: dek,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("storage: aws kms encrypt failed: %w", err)
+	}
+	return out.CiphertextBlob, nil
+}
+
+func (w *awsKMSKeyWrapper) UnwrapKey(ctx context.Context, wrapped []byte) ([]byte, error) {
+	out, err := w.client.Decrypt(ctx, &kms.DecryptInput{
+		KeyId:          &w.keyID,
+		CiphertextBlob: wrapped,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("storage: aws kms decrypt failed: %w", err)
+	}
+	return out.Plaintext, nil
+}
+
+// gcpKMSKeyWrapper wraps data keys with a Google Cloud KMS key, identified
+// by cfg.KMSKeyID as a full
+// projects/*/locations/*/keyRings/*/cryptoKeys/* resource name.
+type gcpKMSKeyWrapper struct {
+	client *gcpkms.KeyManagementClient
+	keyID  string
+}
+
+func newGCPKMSKeyWrapper(keyID string) (*gcpKMSKeyWrapper, error) {
+	if keyID == "" {
+		return nil, errors.New("storage: storage.encryption.kms_key_id is required when kms_provider is \"gcp\"")
+	}
+	client, err := gcpkms.NewKeyManagementClient(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("storage: failed to create gcp kms client: %w", err)
+	}
+	return &gcpKMSKeyWrapper{client: client, keyID: keyID}, nil
+}
+
+func (w *gcpKMSKeyWrapper) WrapKey(ctx context.Context, dek []byte) ([]byte, error) {
+	resp, err := w.client.Encrypt(ctx, &gcpkmspb.EncryptRequest{
+		Name:      w.keyID,
+		Plaintext: dek,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("storage: gcp kms encrypt failed: %w", err)
+	}
+	return resp.Ciphertext, nil
+}
+
+func (w *gcpKMSKeyWrapper) UnwrapKey(ctx context.Context, wrapped []byte) ([]byte, error) {
+	resp, err := w.client.Decrypt(ctx, &gcpkmspb.DecryptRequest{
+		Name:       w.keyID,
+		Ciphertext: wrapped,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("storage: gcp kms decrypt failed: %w", err)
+	}
+	return resp.Plaintext, nil
+}
+
+// EncryptingBackend wraps another Backend so every Put generates a fresh
+// per-object AES-256-GCM data key, encrypts the body with it, and stores
+// the wrapped key alongside the ciphertext as a small header; Get reverses
+// this transparently. Wrapping at the Backend level (rather than inside
+// any one driver) means dedup in chunkstore still works: Exists is checked
+// against the plaintext content hash used as the key, not the ciphertext.
+type EncryptingBackend struct {
+	Backend
+	wrapper KeyWrapper
+}
+
+// NewEncryptingBackend wraps backend for client-side encryption using
+// wrapper to protect each object's data key.
+func NewEncryptingBackend(backend Backend, wrapper KeyWrapper) *EncryptingBackend {
+	return &EncryptingBackend{Backend: backend, wrapper: wrapper}
+}
+
+func (e *EncryptingBackend) Put(ctx context.Context, key string, r io.Reader, contentType string) (string, string, error) {
+	plaintext, err := io.ReadAll(r)
+	if err != nil {
+		return "", "", fmt.Errorf("storage: failed to read object for encryption: %w", err)
+	}
+
+	dek := make([]byte, 32)
+	if _, err := rand.Read(dek); err != nil {
+		return "", "", fmt.Errorf("storage: failed to generate data key: %w", err)
+	}
+	block, err := aes.NewCipher(dek)
+	if err != nil {
+		return "", "", fmt.Errorf("storage: failed to build object cipher: %w", err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return "", "", fmt.Errorf("storage: failed to build object AEAD: %w", err)
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", "", fmt.Errorf("storage: failed to generate object nonce: %w", err)
+	}
+	ciphertext := gcm.Seal(nil, nonce, plaintext, nil)
+
+	wrappedDEK, err := e.wrapper.WrapKey(ctx, dek)
+	if err != nil {
+		return "", "", fmt.Errorf("storage: failed to wrap data key: %w", err)
+	}
+
+	body := encodeEncryptionHeader(wrappedDEK, nonce, ciphertext)
+	return e.Backend.Put(ctx, key, bytes.NewReader(body), contentType)
+}
+
+func (e *EncryptingBackend) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	rc, err := e.Backend.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	raw, err := io.ReadAll(rc)
+	if err != nil {
+		return nil, fmt.Errorf("storage: failed to read encrypted object: %w", err)
+	}
+
+	wrappedDEK, nonce, ciphertext, err := decodeEncryptionHeader(raw)
+	if err != nil {
+		return nil, fmt.Errorf("storage: malformed encrypted object %s: %w", key, err)
+	}
+
+	dek, err := e.wrapper.UnwrapKey(ctx, wrappedDEK)
+	if err != nil {
+		return nil, fmt.Errorf("storage: failed to unwrap data key for %s: %w", key, err)
+	}
+	block, err := aes.NewCipher(dek)
+	if err != nil {
+		return nil, fmt.Errorf("storage: failed to build object cipher for %s: %w", key, err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("storage: failed to build object AEAD for %s: %w", key, err)
+	}
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, fmt.Errorf("storage: failed to decrypt %s, data may be corrupt or tampered with: %w", key, err)
+	}
+
+	return io.NopCloser(bytes.NewReader(plaintext)), nil
+}
+
+// PresignGet always returns ErrPresignNotSupported: a presigned URL hands
+// the raw ciphertext straight to the client, bypassing the decryption Get
+// does here, so every download of a client-side-encrypted object must be
+// proxied through the API.
+func (e *EncryptingBackend) PresignGet(context.Context, string, time.Duration) (string, error) {
+	return "", ErrPresignNotSupported
+}
+
+// encodeEncryptionHeader lays out an encrypted object as:
+// magic | 2-byte wrapped-key length | wrapped key | nonce | ciphertext.
+// The nonce is fixed at the GCM standard 12 bytes, so it isn't length-prefixed.
+func encodeEncryptionHeader(wrappedDEK, nonce, ciphertext []byte) []byte {
+	out := make([]byte, 0, len(encryptionHeaderMagic)+2+len(wrappedDEK)+len(nonce)+len(ciphertext))
+	out = append(out, encryptionHeaderMagic...)
+	out = binary.BigEndian.AppendUint16(out, uint16(len(wrappedDEK)))
+	out = append(out, wrappedDEK...)
+	out = append(out, nonce...)
+	out = append(out, ciphertext...)
+	return out
+}
+
+func decodeEncryptionHeader(raw []byte) (wrappedDEK, nonce, ciphertext []byte, err error) {
+	const nonceSize = 12
+	magicLen := len(encryptionHeaderMagic)
+	if len(raw) < magicLen+2 || string(raw[:magicLen]) != encryptionHeaderMagic {
+		return nil, nil, nil, errors.New("missing or invalid encryption header")
+	}
+	pos := magicLen
+	wrappedLen := int(binary.BigEndian.Uint16(raw[pos : pos+2]))
+	pos += 2
+	if len(raw) < pos+wrappedLen+nonceSize {
+		return nil, nil, nil, errors.New("truncated encryption header")
+	}
+	wrappedDEK = raw[pos : pos+wrappedLen]
+	pos += wrappedLen
+	nonce = raw[pos : pos+nonceSize]
+	pos += nonceSize
+	ciphertext = raw[pos:]
+	return wrappedDEK, nonce, ciphertext, nil
+}
@@ -0,0 +1,99 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/Parallels/pd-ai-agents-registry/internal/config"
+)
+
+// FilesystemBackend stores objects as files under a base directory on local
+// disk. It has no concept of presigned URLs, so PresignGet always returns
+// ErrPresignNotSupported and callers are expected to stream via Get instead.
+type FilesystemBackend struct {
+	baseDir string
+}
+
+// NewFilesystemBackend builds a FilesystemBackend rooted at cfg.BaseDir,
+// creating the directory if it does not already exist.
+func NewFilesystemBackend(cfg config.FilesystemConfig) (*FilesystemBackend, error) {
+	baseDir := cfg.BaseDir
+	if baseDir == "" {
+		baseDir = "./data"
+	}
+
+	if err := os.MkdirAll(baseDir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create storage directory: %w", err)
+	}
+
+	return &FilesystemBackend{baseDir: baseDir}, nil
+}
+
+func (f *FilesystemBackend) path(key string) string {
+	return filepath.Join(f.baseDir, filepath.FromSlash(key))
+}
+
+func (f *FilesystemBackend) Put(ctx context.Context, key string, r io.Reader, contentType string) (string, string, error) {
+	dest := f.path(key)
+	if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+		return "", "", fmt.Errorf("failed to create directory for %s: %w", key, err)
+	}
+
+	out, err := os.Create(dest)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to create file %s: %w", key, err)
+	}
+	defer out.Close()
+
+	if _, err := io.Copy(out, r); err != nil {
+		return "", "", fmt.Errorf("failed to write file %s: %w", key, err)
+	}
+
+	// No external URL scheme for local disk; callers stream through Get.
+	return "", "", nil
+}
+
+func (f *FilesystemBackend) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	file, err := os.Open(f.path(key))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("object not found: %s", key)
+		}
+		return nil, fmt.Errorf("failed to open file %s: %w", key, err)
+	}
+	return file, nil
+}
+
+func (f *FilesystemBackend) Delete(ctx context.Context, key string) error {
+	if err := os.Remove(f.path(key)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete file %s: %w", key, err)
+	}
+	return nil
+}
+
+func (f *FilesystemBackend) Exists(ctx context.Context, key string) (bool, error) {
+	_, err := os.Stat(f.path(key))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to stat file %s: %w", key, err)
+	}
+	return true, nil
+}
+
+func (f *FilesystemBackend) Size(ctx context.Context, key string) (int64, error) {
+	info, err := os.Stat(f.path(key))
+	if err != nil {
+		return 0, fmt.Errorf("failed to stat file %s: %w", key, err)
+	}
+	return info.Size(), nil
+}
+
+func (f *FilesystemBackend) PresignGet(ctx context.Context, key string, ttl time.Duration) (string, error) {
+	return "", ErrPresignNotSupported
+}
@@ -0,0 +1,138 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	gcs "cloud.google.com/go/storage"
+	"github.com/Parallels/pd-ai-agents-registry/internal/config"
+	"golang.org/x/oauth2/google"
+	"google.golang.org/api/option"
+)
+
+// GCSBackend stores objects in a Google Cloud Storage bucket.
+type GCSBackend struct {
+	client   *gcs.Client
+	bucket   string
+	signerID string
+	signerPK []byte
+}
+
+// NewGCSBackend builds a GCSBackend. When cfg.CredentialsFile is set, it is
+// also parsed for the service account email and private key needed to mint
+// signed URLs; without it, PresignGet returns ErrPresignNotSupported and
+// callers fall back to streaming through Get.
+func NewGCSBackend(cfg config.GCSConfig) (*GCSBackend, error) {
+	if cfg.Bucket == "" {
+		return nil, errors.New("gcs: bucket is required")
+	}
+
+	var opts []option.ClientOption
+	var signerID string
+	var signerPK []byte
+	if cfg.CredentialsFile != "" {
+		raw, err := os.ReadFile(cfg.CredentialsFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read gcs credentials file: %w", err)
+		}
+		jwtCfg, err := google.JWTConfigFromJSON(raw, gcs.ScopeReadWrite)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse gcs credentials file: %w", err)
+		}
+		signerID = jwtCfg.Email
+		signerPK = jwtCfg.PrivateKey
+		opts = append(opts, option.WithCredentialsJSON(raw))
+	}
+
+	client, err := gcs.NewClient(context.Background(), opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create gcs client: %w", err)
+	}
+
+	return &GCSBackend{client: client, bucket: cfg.Bucket, signerID: signerID, signerPK: signerPK}, nil
+}
+
+func (g *GCSBackend) object(key string) *gcs.ObjectHandle {
+	return g.client.Bucket(g.bucket).Object(key)
+}
+
+func (g *GCSBackend) Put(ctx context.Context, key string, r io.Reader, contentType string) (string, string, error) {
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	w := g.object(key).NewWriter(ctx)
+	w.ContentType = contentType
+	if _, err := io.Copy(w, r); err != nil {
+		w.Close()
+		return "", "", fmt.Errorf("gcs upload failed: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return "", "", fmt.Errorf("gcs upload failed: %w", err)
+	}
+
+	url, err := g.PresignGet(ctx, key, 24*time.Hour)
+	if err != nil && !errors.Is(err, ErrPresignNotSupported) {
+		return "", "", err
+	}
+
+	return url, w.Attrs().Etag, nil
+}
+
+func (g *GCSBackend) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	r, err := g.object(key).NewReader(ctx)
+	if err != nil {
+		if errors.Is(err, gcs.ErrObjectNotExist) {
+			return nil, fmt.Errorf("object not found: %s", key)
+		}
+		return nil, fmt.Errorf("gcs download failed: %w", err)
+	}
+	return r, nil
+}
+
+func (g *GCSBackend) Delete(ctx context.Context, key string) error {
+	if err := g.object(key).Delete(ctx); err != nil && !errors.Is(err, gcs.ErrObjectNotExist) {
+		return fmt.Errorf("gcs delete failed: %w", err)
+	}
+	return nil
+}
+
+func (g *GCSBackend) Exists(ctx context.Context, key string) (bool, error) {
+	_, err := g.object(key).Attrs(ctx)
+	if err != nil {
+		if errors.Is(err, gcs.ErrObjectNotExist) {
+			return false, nil
+		}
+		return false, fmt.Errorf("gcs stat failed: %w", err)
+	}
+	return true, nil
+}
+
+func (g *GCSBackend) Size(ctx context.Context, key string) (int64, error) {
+	attrs, err := g.object(key).Attrs(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("gcs stat failed: %w", err)
+	}
+	return attrs.Size, nil
+}
+
+func (g *GCSBackend) PresignGet(ctx context.Context, key string, ttl time.Duration) (string, error) {
+	if g.signerID == "" {
+		return "", ErrPresignNotSupported
+	}
+
+	url, err := gcs.SignedURL(g.bucket, key, &gcs.SignedURLOptions{
+		GoogleAccessID: g.signerID,
+		PrivateKey:     g.signerPK,
+		Method:         "GET",
+		Expires:        time.Now().Add(ttl),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to generate signed URL: %w", err)
+	}
+	return url, nil
+}
@@ -0,0 +1,120 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/Parallels/pd-ai-agents-registry/internal/config"
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+// MinIOBackend talks to a MinIO cluster using the native minio-go client,
+// rather than going through the S3-compatibility layer. This is the driver
+// to reach for in self-hosted/air-gapped deployments that run MinIO with a
+// plain-HTTP endpoint and no region concept.
+type MinIOBackend struct {
+	client *minio.Client
+	bucket string
+}
+
+// NewMinIOBackend builds a MinIOBackend, creating the configured bucket if
+// it doesn't already exist.
+func NewMinIOBackend(cfg config.MinIOConfig) (*MinIOBackend, error) {
+	client, err := minio.New(cfg.Endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(cfg.AccessKeyID, cfg.SecretAccessKey, ""),
+		Secure: !cfg.UseHTTP,
+		Region: cfg.Region,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create minio client: %w", err)
+	}
+
+	ctx := context.Background()
+	exists, err := client.BucketExists(ctx, cfg.Bucket)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check minio bucket: %w", err)
+	}
+	if !exists {
+		if err := client.MakeBucket(ctx, cfg.Bucket, minio.MakeBucketOptions{Region: cfg.Region}); err != nil {
+			return nil, fmt.Errorf("failed to create minio bucket: %w", err)
+		}
+	}
+
+	return &MinIOBackend{client: client, bucket: cfg.Bucket}, nil
+}
+
+func (m *MinIOBackend) Put(ctx context.Context, key string, r io.Reader, contentType string) (string, string, error) {
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	info, err := m.client.PutObject(ctx, m.bucket, key, r, -1, minio.PutObjectOptions{ContentType: contentType})
+	if err != nil {
+		return "", "", fmt.Errorf("minio upload failed: %w", err)
+	}
+
+	url, err := m.PresignGet(ctx, key, 24*time.Hour)
+	if err != nil {
+		return "", "", err
+	}
+
+	return url, info.ETag, nil
+}
+
+func (m *MinIOBackend) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	obj, err := m.client.GetObject(ctx, m.bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("minio download failed: %w", err)
+	}
+	// GetObject doesn't make a request until the first read/stat, so check
+	// existence up front to return a clean "not found" error.
+	if _, err := obj.Stat(); err != nil {
+		obj.Close()
+		if errResp := minio.ToErrorResponse(err); errResp.Code == "NoSuchKey" {
+			return nil, fmt.Errorf("object not found: %s", key)
+		}
+		return nil, fmt.Errorf("minio download failed: %w", err)
+	}
+	return obj, nil
+}
+
+func (m *MinIOBackend) Delete(ctx context.Context, key string) error {
+	if err := m.client.RemoveObject(ctx, m.bucket, key, minio.RemoveObjectOptions{}); err != nil {
+		return fmt.Errorf("minio delete failed: %w", err)
+	}
+	return nil
+}
+
+func (m *MinIOBackend) Exists(ctx context.Context, key string) (bool, error) {
+	_, err := m.client.StatObject(ctx, m.bucket, key, minio.StatObjectOptions{})
+	if err != nil {
+		if errResp := minio.ToErrorResponse(err); errResp.Code == "NoSuchKey" {
+			return false, nil
+		}
+		return false, fmt.Errorf("minio stat failed: %w", err)
+	}
+	return true, nil
+}
+
+func (m *MinIOBackend) Size(ctx context.Context, key string) (int64, error) {
+	info, err := m.client.StatObject(ctx, m.bucket, key, minio.StatObjectOptions{})
+	if err != nil {
+		return 0, fmt.Errorf("minio stat failed: %w", err)
+	}
+	return info.Size, nil
+}
+
+func (m *MinIOBackend) PresignGet(ctx context.Context, key string, ttl time.Duration) (string, error) {
+	if ttl > 7*24*time.Hour {
+		return "", errors.New("minio: presigned URL ttl cannot exceed 7 days")
+	}
+	u, err := m.client.PresignedGetObject(ctx, m.bucket, key, ttl, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate presigned URL: %w", err)
+	}
+	return u.String(), nil
+}
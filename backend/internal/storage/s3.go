@@ -0,0 +1,379 @@
+package storage
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/Parallels/pd-ai-agents-registry/internal/config"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
+)
+
+// sha256TagKey is the S3 object tag Put stores the uploaded content's
+// sha256 digest under, so it can be checked against on download without
+// relying solely on the hash recorded in MongoDB.
+const sha256TagKey = "pd-ai-sha256"
+
+// S3Backend stores objects in S3 or an S3-compatible service such as MinIO.
+type S3Backend struct {
+	client *s3.Client
+	bucket string
+	// sse and sseKMSKeyID configure S3's own server-side encryption, set on
+	// every PutObject when storage.encryption.mode is "sse-s3" or
+	// "sse-kms". "client-side" mode is handled a layer up by
+	// EncryptingBackend and never reaches here.
+	sse         types.ServerSideEncryption
+	sseKMSKeyID string
+}
+
+// NewS3Backend builds an S3Backend, pointing it at a custom endpoint (and
+// forcing path-style addressing) when cfg.Endpoint is set, which is how we
+// talk to MinIO in self-hosted deployments.
+func NewS3Backend(cfg config.S3Config, enc config.EncryptionConfig) (*S3Backend, error) {
+	var options []func(*awsconfig.LoadOptions) error
+
+	options = append(options, awsconfig.WithRegion(cfg.Region))
+	options = append(options, awsconfig.WithCredentialsProvider(
+		credentials.NewStaticCredentialsProvider(
+			cfg.AccessKeyID,
+			cfg.SecretAccessKey,
+			"",
+		),
+	))
+
+	awsCfg, err := awsconfig.LoadDefaultConfig(context.TODO(), options...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS config: %w", err)
+	}
+
+	clientOptions := []func(*s3.Options){
+		func(o *s3.Options) {
+			if cfg.Endpoint != "" {
+				o.BaseEndpoint = aws.String(cfg.Endpoint)
+				o.UsePathStyle = true
+				if strings.Contains(cfg.Endpoint, "localhost") || strings.Contains(cfg.Endpoint, "127.0.0.1") {
+					o.EndpointOptions.DisableHTTPS = true
+				}
+			}
+		},
+	}
+
+	client := s3.NewFromConfig(awsCfg, clientOptions...)
+
+	_, err = client.HeadBucket(context.TODO(), &s3.HeadBucketInput{
+		Bucket: aws.String(cfg.Bucket),
+	})
+	if err != nil {
+		var apiErr smithy.APIError
+		if errors.As(err, &apiErr) {
+			return nil, fmt.Errorf("bucket access error: %s: %s", apiErr.ErrorCode(), apiErr.ErrorMessage())
+		}
+		return nil, fmt.Errorf("failed to access bucket: %w", err)
+	}
+
+	backend := &S3Backend{
+		client: client,
+		bucket: cfg.Bucket,
+	}
+	switch enc.Mode {
+	case "sse-s3":
+		backend.sse = types.ServerSideEncryptionAes256
+	case "sse-kms":
+		backend.sse = types.ServerSideEncryptionAwsKms
+		backend.sseKMSKeyID = enc.KMSKeyID
+	}
+
+	return backend, nil
+}
+
+func (s *S3Backend) Put(ctx context.Context, key string, r io.Reader, contentType string) (string, string, error) {
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	// Hash the body as it streams up so the digest can be stored as an S3
+	// object tag alongside the upload, letting Download verify it against
+	// storage's own record instead of trusting MongoDB alone.
+	hasher := sha256.New()
+	input := &s3.PutObjectInput{
+		Bucket:       aws.String(s.bucket),
+		Key:          aws.String(key),
+		Body:         io.TeeReader(r, hasher),
+		ContentType:  aws.String(contentType),
+		StorageClass: types.StorageClassStandard,
+	}
+	if s.sse != "" {
+		input.ServerSideEncryption = s.sse
+		if s.sseKMSKeyID != "" {
+			input.SSEKMSKeyId = aws.String(s.sseKMSKeyID)
+		}
+	}
+
+	out, err := s.client.PutObject(ctx, input)
+	if err != nil {
+		var apiErr smithy.APIError
+		if errors.As(err, &apiErr) {
+			return "", "", fmt.Errorf("s3 upload failed: %s: %s", apiErr.ErrorCode(), apiErr.ErrorMessage())
+		}
+		return "", "", fmt.Errorf("s3 upload failed: %w", err)
+	}
+
+	tagValue := fmt.Sprintf("%x", hasher.Sum(nil))
+	if _, err := s.client.PutObjectTagging(ctx, &s3.PutObjectTaggingInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+		Tagging: &types.Tagging{
+			TagSet: []types.Tag{{Key: aws.String(sha256TagKey), Value: aws.String(tagValue)}},
+		},
+	}); err != nil {
+		return "", "", fmt.Errorf("s3 failed to tag object with integrity hash: %w", err)
+	}
+
+	url, err := s.PresignGet(ctx, key, 24*time.Hour)
+	if err != nil {
+		return "", "", err
+	}
+
+	etag := ""
+	if out.ETag != nil {
+		etag = strings.Trim(*out.ETag, `"`)
+	}
+
+	return url, etag, nil
+}
+
+func (s *S3Backend) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	input := &s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	}
+
+	result, err := s.client.GetObject(ctx, input)
+	if err != nil {
+		var apiErr smithy.APIError
+		if errors.As(err, &apiErr) {
+			if apiErr.ErrorCode() == "NoSuchKey" {
+				return nil, fmt.Errorf("object not found: %s", key)
+			}
+			return nil, fmt.Errorf("s3 download failed: %s: %s", apiErr.ErrorCode(), apiErr.ErrorMessage())
+		}
+		return nil, fmt.Errorf("s3 download failed: %w", err)
+	}
+
+	return result.Body, nil
+}
+
+func (s *S3Backend) Size(ctx context.Context, key string) (int64, error) {
+	input := &s3.HeadObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	}
+
+	result, err := s.client.HeadObject(ctx, input)
+	if err != nil {
+		return 0, fmt.Errorf("s3 size failed: %w", err)
+	}
+
+	return *result.ContentLength, nil
+}
+
+func (s *S3Backend) Delete(ctx context.Context, key string) error {
+	input := &s3.DeleteObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	}
+
+	_, err := s.client.DeleteObject(ctx, input)
+	if err != nil {
+		var apiErr smithy.APIError
+		if errors.As(err, &apiErr) {
+			return fmt.Errorf("s3 delete failed: %s: %s", apiErr.ErrorCode(), apiErr.ErrorMessage())
+		}
+		return fmt.Errorf("s3 delete failed: %w", err)
+	}
+
+	waiter := s3.NewObjectNotExistsWaiter(s.client)
+	err = waiter.Wait(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	}, 30*time.Second)
+	if err != nil {
+		return fmt.Errorf("error waiting for object deletion: %w", err)
+	}
+
+	return nil
+}
+
+func (s *S3Backend) PresignGet(ctx context.Context, key string, ttl time.Duration) (string, error) {
+	presignClient := s3.NewPresignClient(s.client)
+
+	input := &s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	}
+
+	request, err := presignClient.PresignGetObject(ctx, input, func(options *s3.PresignOptions) {
+		options.Expires = ttl
+	})
+	if err != nil {
+		var apiErr smithy.APIError
+		if errors.As(err, &apiErr) {
+			return "", fmt.Errorf("failed to generate presigned URL: %s: %s", apiErr.ErrorCode(), apiErr.ErrorMessage())
+		}
+		return "", fmt.Errorf("failed to generate presigned URL: %w", err)
+	}
+
+	return request.URL, nil
+}
+
+// CreateMultipartUpload starts a multipart upload for key, letting the
+// client PUT parts directly to S3 instead of buffering the whole file
+// through the API server.
+func (s *S3Backend) CreateMultipartUpload(ctx context.Context, key, contentType string) (string, error) {
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	out, err := s.client.CreateMultipartUpload(ctx, &s3.CreateMultipartUploadInput{
+		Bucket:      aws.String(s.bucket),
+		Key:         aws.String(key),
+		ContentType: aws.String(contentType),
+	})
+	if err != nil {
+		var apiErr smithy.APIError
+		if errors.As(err, &apiErr) {
+			return "", fmt.Errorf("s3 create multipart upload failed: %s: %s", apiErr.ErrorCode(), apiErr.ErrorMessage())
+		}
+		return "", fmt.Errorf("s3 create multipart upload failed: %w", err)
+	}
+
+	return aws.ToString(out.UploadId), nil
+}
+
+func (s *S3Backend) PresignUploadPart(ctx context.Context, key, uploadID string, partNumber int32, ttl time.Duration) (string, error) {
+	presignClient := s3.NewPresignClient(s.client)
+
+	request, err := presignClient.PresignUploadPart(ctx, &s3.UploadPartInput{
+		Bucket:     aws.String(s.bucket),
+		Key:        aws.String(key),
+		UploadId:   aws.String(uploadID),
+		PartNumber: aws.Int32(partNumber),
+	}, func(options *s3.PresignOptions) {
+		options.Expires = ttl
+	})
+	if err != nil {
+		var apiErr smithy.APIError
+		if errors.As(err, &apiErr) {
+			return "", fmt.Errorf("failed to presign upload part: %s: %s", apiErr.ErrorCode(), apiErr.ErrorMessage())
+		}
+		return "", fmt.Errorf("failed to presign upload part: %w", err)
+	}
+
+	return request.URL, nil
+}
+
+func (s *S3Backend) CompleteMultipartUpload(ctx context.Context, key, uploadID string, parts []CompletedPart) (string, string, error) {
+	completed := make([]types.CompletedPart, len(parts))
+	for i, p := range parts {
+		completed[i] = types.CompletedPart{
+			PartNumber: aws.Int32(p.PartNumber),
+			ETag:       aws.String(p.ETag),
+		}
+	}
+
+	out, err := s.client.CompleteMultipartUpload(ctx, &s3.CompleteMultipartUploadInput{
+		Bucket:          aws.String(s.bucket),
+		Key:             aws.String(key),
+		UploadId:        aws.String(uploadID),
+		MultipartUpload: &types.CompletedMultipartUpload{Parts: completed},
+	})
+	if err != nil {
+		var apiErr smithy.APIError
+		if errors.As(err, &apiErr) {
+			return "", "", fmt.Errorf("s3 complete multipart upload failed: %s: %s", apiErr.ErrorCode(), apiErr.ErrorMessage())
+		}
+		return "", "", fmt.Errorf("s3 complete multipart upload failed: %w", err)
+	}
+
+	url, err := s.PresignGet(ctx, key, 24*time.Hour)
+	if err != nil {
+		return "", "", err
+	}
+
+	etag := ""
+	if out.ETag != nil {
+		etag = strings.Trim(*out.ETag, `"`)
+	}
+
+	return url, etag, nil
+}
+
+func (s *S3Backend) AbortMultipartUpload(ctx context.Context, key, uploadID string) error {
+	_, err := s.client.AbortMultipartUpload(ctx, &s3.AbortMultipartUploadInput{
+		Bucket:   aws.String(s.bucket),
+		Key:      aws.String(key),
+		UploadId: aws.String(uploadID),
+	})
+	if err != nil {
+		var apiErr smithy.APIError
+		if errors.As(err, &apiErr) {
+			return fmt.Errorf("s3 abort multipart upload failed: %s: %s", apiErr.ErrorCode(), apiErr.ErrorMessage())
+		}
+		return fmt.Errorf("s3 abort multipart upload failed: %w", err)
+	}
+	return nil
+}
+
+func (s *S3Backend) Exists(ctx context.Context, key string) (bool, error) {
+	input := &s3.HeadObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	}
+
+	_, err := s.client.HeadObject(ctx, input)
+	if err != nil {
+		var apiErr smithy.APIError
+		if errors.As(err, &apiErr) {
+			if apiErr.ErrorCode() == "NotFound" {
+				return false, nil
+			}
+			return false, fmt.Errorf("s3 head object failed: %s: %s", apiErr.ErrorCode(), apiErr.ErrorMessage())
+		}
+		return false, fmt.Errorf("s3 head object failed: %w", err)
+	}
+
+	return true, nil
+}
+
+// GetIntegrityTag returns the sha256 digest Put recorded as an object tag
+// for key, so callers can catch corruption that happened purely inside S3
+// (e.g. a tampered or bit-rotted object) before paying for a full download.
+// Returns "" if the object predates this tagging or carries no such tag.
+func (s *S3Backend) GetIntegrityTag(ctx context.Context, key string) (string, error) {
+	out, err := s.client.GetObjectTagging(ctx, &s3.GetObjectTaggingInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		var apiErr smithy.APIError
+		if errors.As(err, &apiErr) {
+			return "", fmt.Errorf("s3 get object tagging failed: %s: %s", apiErr.ErrorCode(), apiErr.ErrorMessage())
+		}
+		return "", fmt.Errorf("s3 get object tagging failed: %w", err)
+	}
+	for _, tag := range out.TagSet {
+		if aws.ToString(tag.Key) == sha256TagKey {
+			return aws.ToString(tag.Value), nil
+		}
+	}
+	return "", nil
+}
@@ -0,0 +1,67 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Parallels/pd-ai-agents-registry/internal/config"
+	"github.com/hibiken/asynq"
+)
+
+// Client enqueues tasks onto the Redis-backed queue. It is safe for
+// concurrent use and is shared by every request handler.
+type Client struct {
+	client *asynq.Client
+}
+
+// NewClient builds a Client connected to the configured Redis instance.
+func NewClient(cfg config.RedisConfig) *Client {
+	return &Client{
+		client: asynq.NewClient(asynq.RedisClientOpt{
+			Addr:     cfg.Addr,
+			Password: cfg.Password,
+			DB:       cfg.DB,
+		}),
+	}
+}
+
+// Close releases the underlying Redis connection.
+func (c *Client) Close() error {
+	return c.client.Close()
+}
+
+// EnqueueProcessPackage enqueues a package.process task.
+func (c *Client) EnqueueProcessPackage(ctx context.Context, payload ProcessPackagePayload) error {
+	task, err := NewProcessPackageTask(payload)
+	if err != nil {
+		return err
+	}
+	if _, err := c.client.EnqueueContext(ctx, task); err != nil {
+		return fmt.Errorf("failed to enqueue %s: %w", TypePackageProcess, err)
+	}
+	return nil
+}
+
+// EnqueueReprocessPackage enqueues a package.reprocess task.
+func (c *Client) EnqueueReprocessPackage(ctx context.Context, payload ReprocessPackagePayload) error {
+	task, err := NewReprocessPackageTask(payload)
+	if err != nil {
+		return err
+	}
+	if _, err := c.client.EnqueueContext(ctx, task); err != nil {
+		return fmt.Errorf("failed to enqueue %s: %w", TypePackageReprocess, err)
+	}
+	return nil
+}
+
+// EnqueueDeleteBlob enqueues a package.delete_blob task.
+func (c *Client) EnqueueDeleteBlob(ctx context.Context, payload DeleteBlobPayload) error {
+	task, err := NewDeleteBlobTask(payload)
+	if err != nil {
+		return err
+	}
+	if _, err := c.client.EnqueueContext(ctx, task); err != nil {
+		return fmt.Errorf("failed to enqueue %s: %w", TypePackageDeleteBlob, err)
+	}
+	return nil
+}
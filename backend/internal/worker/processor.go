@@ -0,0 +1,230 @@
+package worker
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/Parallels/pd-ai-agents-registry/internal/chunkstore"
+	"github.com/Parallels/pd-ai-agents-registry/internal/db/mongodb"
+	"github.com/Parallels/pd-ai-agents-registry/internal/logger"
+	"github.com/Parallels/pd-ai-agents-registry/internal/models"
+	"github.com/Parallels/pd-ai-agents-registry/internal/signing"
+	"github.com/Parallels/pd-ai-agents-registry/internal/storage"
+	"github.com/hibiken/asynq"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// manifestFilename is the file expected at the root of an uploaded package
+// archive describing the agents it provides.
+const manifestFilename = "agents.json"
+
+// forbiddenSuffixes blocks archive entries that have no business inside an
+// agent package and would otherwise be extracted verbatim by clients.
+var forbiddenSuffixes = []string{".exe", ".dll", ".so", ".dylib"}
+
+// manifest mirrors the subset of agents.json that the registry cares about.
+type manifest struct {
+	Requirements []string                 `json:"requirements"`
+	Agents       []models.AgentDefinition `json:"agents"`
+	// Signature is an optional detached signature over the canonical
+	// `{name}@{version}/{filename}:{sha256}` string for the archive itself.
+	// When present, it must verify against a trusted publisher key or the
+	// upload is rejected outright.
+	Signature *models.FileSignature `json:"signature,omitempty"`
+}
+
+// Processor implements the asynq task handlers that back the async
+// upload-processing pipeline.
+type Processor struct {
+	db      *mongodb.Client
+	storage storage.Backend
+	logger  *logger.Logger
+	signing *signing.Verifier
+}
+
+// NewProcessor builds a Processor.
+func NewProcessor(db *mongodb.Client, backend storage.Backend, log *logger.Logger, verifier *signing.Verifier) *Processor {
+	return &Processor{db: db, storage: backend, logger: log, signing: verifier}
+}
+
+// RegisterHandlers wires every task type this package knows about onto mux.
+func (p *Processor) RegisterHandlers(mux *asynq.ServeMux) {
+	mux.HandleFunc(TypePackageProcess, p.handleProcessPackage)
+	mux.HandleFunc(TypePackageReprocess, p.handleReprocessPackage)
+	mux.HandleFunc(TypePackageDeleteBlob, p.handleDeleteBlob)
+}
+
+func (p *Processor) handleProcessPackage(ctx context.Context, t *asynq.Task) error {
+	var payload ProcessPackagePayload
+	if err := json.Unmarshal(t.Payload(), &payload); err != nil {
+		return fmt.Errorf("%s: invalid payload: %w", TypePackageProcess, err)
+	}
+
+	packageID, err := primitive.ObjectIDFromHex(payload.PackageID)
+	if err != nil {
+		return fmt.Errorf("%s: invalid package id: %w", TypePackageProcess, err)
+	}
+
+	if err := p.db.SetVersionProcessingState(ctx, packageID, payload.Version, models.ProcessingStateProcessing, ""); err != nil {
+		return fmt.Errorf("%s: failed to mark version processing: %w", TypePackageProcess, err)
+	}
+
+	m, sha256Hex, err := p.extractManifest(ctx, payload.FileKey, payload.Chunks)
+	if err == nil && m.Signature != nil {
+		message := signing.CanonicalMessage(payload.PackageName, payload.Version, payload.Filename, sha256Hex)
+		sig, decodeErr := base64.StdEncoding.DecodeString(m.Signature.Value)
+		if decodeErr != nil || !p.signing.Verify(m.Signature.KeyID, message, sig) {
+			err = fmt.Errorf("manifest signature failed verification against key %q", m.Signature.KeyID)
+		}
+	}
+	if err != nil {
+		p.logger.Error("package processing failed", "error", err, "package_id", payload.PackageID, "version", payload.Version)
+		if markErr := p.db.SetVersionProcessingState(ctx, packageID, payload.Version, models.ProcessingStateFailed, err.Error()); markErr != nil {
+			p.logger.Error("failed to mark version failed", "error", markErr)
+		}
+		// Manifest/content/signature problems are not retryable; don't return
+		// an error so asynq treats the task as done rather than retrying forever.
+		return nil
+	}
+
+	if err := p.db.ApplyManifest(ctx, packageID, payload.Version, m.Requirements, m.Agents); err != nil {
+		return fmt.Errorf("%s: failed to store manifest: %w", TypePackageProcess, err)
+	}
+	if err := p.db.SetVersionProcessingState(ctx, packageID, payload.Version, models.ProcessingStateReady, ""); err != nil {
+		return fmt.Errorf("%s: failed to mark version ready: %w", TypePackageProcess, err)
+	}
+
+	return nil
+}
+
+func (p *Processor) handleReprocessPackage(ctx context.Context, t *asynq.Task) error {
+	var payload ReprocessPackagePayload
+	if err := json.Unmarshal(t.Payload(), &payload); err != nil {
+		return fmt.Errorf("%s: invalid payload: %w", TypePackageReprocess, err)
+	}
+
+	packageID, err := primitive.ObjectIDFromHex(payload.PackageID)
+	if err != nil {
+		return fmt.Errorf("%s: invalid package id: %w", TypePackageReprocess, err)
+	}
+
+	pkg, err := p.db.GetPackageByID(ctx, packageID)
+	if err != nil {
+		return fmt.Errorf("%s: failed to load package: %w", TypePackageReprocess, err)
+	}
+	if pkg == nil {
+		return fmt.Errorf("%s: package %s not found", TypePackageReprocess, payload.PackageID)
+	}
+
+	ver, err := p.db.GetVersion(ctx, packageID, payload.Version)
+	if err != nil {
+		return fmt.Errorf("%s: failed to load version: %w", TypePackageReprocess, err)
+	}
+	if ver == nil || len(ver.Files) == 0 {
+		return fmt.Errorf("%s: version %s has no files to reprocess", TypePackageReprocess, payload.Version)
+	}
+
+	return p.handleProcessPackage(ctx, mustProcessTask(ProcessPackagePayload{
+		PackageID:   payload.PackageID,
+		PackageName: pkg.Name,
+		Version:     payload.Version,
+		FileKey:     path.Join("packages", pkg.Name, payload.Version, ver.Files[0].Name),
+		Filename:    ver.Files[0].Name,
+		Chunks:      ver.Files[0].Chunks,
+	}))
+}
+
+func (p *Processor) handleDeleteBlob(ctx context.Context, t *asynq.Task) error {
+	var payload DeleteBlobPayload
+	if err := json.Unmarshal(t.Payload(), &payload); err != nil {
+		return fmt.Errorf("%s: invalid payload: %w", TypePackageDeleteBlob, err)
+	}
+
+	if err := p.storage.Delete(ctx, payload.FileKey); err != nil {
+		return fmt.Errorf("%s: failed to delete blob %s: %w", TypePackageDeleteBlob, payload.FileKey, err)
+	}
+	return nil
+}
+
+// extractManifest fetches the uploaded archive (reassembling it from chunks
+// when present, otherwise downloading the single blob at key), parses its
+// agents.json, and returns the archive's sha256 so any declared signature
+// can be verified against it.
+func (p *Processor) extractManifest(ctx context.Context, key string, chunks []string) (*manifest, string, error) {
+	var reader io.ReadCloser
+	var err error
+	if len(chunks) > 0 {
+		reader, err = chunkstore.Open(ctx, p.storage, chunks)
+	} else {
+		reader, err = p.storage.Get(ctx, key)
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to fetch uploaded file: %w", err)
+	}
+	defer reader.Close()
+
+	hasher := sha256.New()
+	data, err := io.ReadAll(io.TeeReader(reader, hasher))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read uploaded file: %w", err)
+	}
+	sha256Hex := fmt.Sprintf("%x", hasher.Sum(nil))
+
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, sha256Hex, fmt.Errorf("uploaded file is not a valid archive: %w", err)
+	}
+
+	var manifestFile *zip.File
+	for _, f := range zr.File {
+		if strings.Contains(f.Name, "..") || strings.HasPrefix(f.Name, "/") {
+			return nil, sha256Hex, fmt.Errorf("archive entry %q escapes the package root", f.Name)
+		}
+		for _, suffix := range forbiddenSuffixes {
+			if strings.HasSuffix(strings.ToLower(f.Name), suffix) {
+				return nil, sha256Hex, fmt.Errorf("archive entry %q has a forbidden file type", f.Name)
+			}
+		}
+		if f.Name == manifestFilename {
+			manifestFile = f
+		}
+	}
+	if manifestFile == nil {
+		return nil, sha256Hex, fmt.Errorf("archive is missing %s", manifestFilename)
+	}
+
+	rc, err := manifestFile.Open()
+	if err != nil {
+		return nil, sha256Hex, fmt.Errorf("failed to open %s: %w", manifestFilename, err)
+	}
+	defer rc.Close()
+
+	var m manifest
+	if err := json.NewDecoder(rc).Decode(&m); err != nil {
+		return nil, sha256Hex, fmt.Errorf("failed to parse %s: %w", manifestFilename, err)
+	}
+	if len(m.Agents) == 0 {
+		return nil, sha256Hex, fmt.Errorf("%s declares no agents", manifestFilename)
+	}
+
+	return &m, sha256Hex, nil
+}
+
+// mustProcessTask builds a package.process task for an in-process call; it
+// only fails on JSON marshalling errors, which cannot happen for this
+// payload shape, so it panics rather than threading an error nobody handles.
+func mustProcessTask(payload ProcessPackagePayload) *asynq.Task {
+	task, err := NewProcessPackageTask(payload)
+	if err != nil {
+		panic(err)
+	}
+	return task
+}
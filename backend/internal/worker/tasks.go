@@ -0,0 +1,75 @@
+// Package worker implements the asynq-backed task queue that moves upload
+// processing (hashing, manifest validation, blob GC) off the HTTP request
+// path so it can retry independently of the client connection.
+package worker
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hibiken/asynq"
+)
+
+// Task type names, used as the asynq.Task.Type().
+const (
+	TypePackageProcess    = "package.process"
+	TypePackageReprocess  = "package.reprocess"
+	TypePackageDeleteBlob = "package.delete_blob"
+)
+
+// ProcessPackagePayload carries the information needed to validate and
+// finalize a freshly uploaded package file.
+type ProcessPackagePayload struct {
+	PackageID   string   `json:"package_id"`
+	PackageName string   `json:"package_name"`
+	Version     string   `json:"version"`
+	FileKey     string   `json:"file_key"`
+	Filename    string   `json:"filename"`
+	// Chunks holds the ordered content-addressed chunk hashes for files
+	// stored via the chunked upload path; empty for legacy single-blob
+	// files, which are fetched via FileKey instead.
+	Chunks []string `json:"chunks,omitempty"`
+}
+
+// ReprocessPackagePayload reruns extraction on an already-stored file, e.g.
+// after a schema change to AgentDefinition parsing.
+type ReprocessPackagePayload struct {
+	PackageID string `json:"package_id"`
+	Version   string `json:"version"`
+}
+
+// DeleteBlobPayload garbage-collects a storage object left orphaned by a
+// deleted package file.
+type DeleteBlobPayload struct {
+	FileKey string `json:"file_key"`
+}
+
+// NewProcessPackageTask builds the task enqueued right after a package
+// upload is persisted to storage.
+func NewProcessPackageTask(payload ProcessPackagePayload) (*asynq.Task, error) {
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal %s payload: %w", TypePackageProcess, err)
+	}
+	return asynq.NewTask(TypePackageProcess, b), nil
+}
+
+// NewReprocessPackageTask builds the task enqueued by the admin "rejudge"
+// endpoint to rerun extraction on an existing version.
+func NewReprocessPackageTask(payload ReprocessPackagePayload) (*asynq.Task, error) {
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal %s payload: %w", TypePackageReprocess, err)
+	}
+	return asynq.NewTask(TypePackageReprocess, b), nil
+}
+
+// NewDeleteBlobTask builds the task enqueued after DeletePackage to GC the
+// underlying storage object asynchronously.
+func NewDeleteBlobTask(payload DeleteBlobPayload) (*asynq.Task, error) {
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal %s payload: %w", TypePackageDeleteBlob, err)
+	}
+	return asynq.NewTask(TypePackageDeleteBlob, b), nil
+}